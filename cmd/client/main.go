@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"orchestrator/internal/auth"
+	"orchestrator/internal/certs"
+	"orchestrator/internal/config"
 	"orchestrator/internal/handlers"
 	"orchestrator/internal/templates"
+	"orchestrator/internal/validation"
 	"orchestrator/pb"
 	"os"
 	"time"
 
 	"github.com/a-h/templ"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
@@ -21,12 +29,27 @@ func main() {
 		fmt.Printf("Execution time: %s\n", time.Since(initTime))
 	}()
 
-	grpcHost := os.Getenv("GRPC_SERVER_HOST")
-	if grpcHost == "" {
-		grpcHost = "localhost"
+	cfg := config.DefaultClient()
+	loaded, _, err := config.Load("client", os.Args[1:], &cfg)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	loaded.Print(os.Stdout)
+
+	creds, err := clientCredentials(cfg.TLS, cfg.AgentHost)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if cfg.AgentToken != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(auth.Bearer(cfg.AgentToken, !cfg.TLS.Insecure)))
 	}
 
-	conn, err := grpc.NewClient(grpcHost+":50051", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(cfg.AgentAddr(), opts...)
 	if err != nil {
 		log.Fatalf("did not connect: %v", err)
 	}
@@ -35,15 +58,31 @@ func main() {
 	orchestratorClient := pb.NewOrchestratorServiceClient(conn)
 	fmt.Println("Started grpc client")
 
-	handler := &handlers.BotHandler{
-		AgentClient: orchestratorClient,
-	}
+	handler := handlers.NewBotHandler(orchestratorClient, validation.NewPolicy(cfg.Git.AllowedSchemes, cfg.Git.AllowedHosts))
 
 	http.HandleFunc("POST /bots/run", handler.RunBotHandler)
+	http.HandleFunc("GET /jobs", handler.ListJobsHandler)
+	http.HandleFunc("POST /jobs/{id}/cancel", handler.CancelJobHandler)
+	http.HandleFunc("GET /jobs/{id}/attach", handler.AttachLogsHandler)
+	http.HandleFunc("GET /jobs/{id}/logs", handler.JobLogsHandler)
 	http.Handle("/", templ.Handler(templates.Layout(templates.DeployForm())))
 
-	fmt.Println("and starting HTTP server on :8080")
+	fmt.Println("and starting HTTP server on " + cfg.ListenAddr)
 
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Fatal(http.ListenAndServe(cfg.ListenAddr, nil))
+}
 
+// clientCredentials conecta ao agente em host com TLS, a menos que
+// tls.insecure esteja ligado.
+func clientCredentials(cfg config.ClientTLS, host string) (credentials.TransportCredentials, error) {
+	if cfg.Insecure {
+		fmt.Println("AVISO: conectando ao agente sem TLS (tls.insecure)")
+		return insecure.NewCredentials(), nil
+	}
+	reloader, err := certs.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(context.Background(), cfg.ReloadInterval)
+	return credentials.NewTLS(reloader.ClientConfig(cfg.ServerName, host)), nil
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"orchestrator/internal/config"
+	"orchestrator/internal/secrets"
+	"os"
+	"strings"
+)
+
+const usage = `uso:
+  secrets [-config ARQUIVO] set NOME [BOT...]  lê o valor da entrada padrão e grava o segredo
+  secrets [-config ARQUIVO] delete NOME        remove o segredo
+  secrets [-config ARQUIVO] list               lista os nomes cadastrados e os bots de cada um
+
+BOT restringe o segredo aos bots que casam com o padrão, ex: 'relatorio-*'.
+Sem nenhum, qualquer bot pode usar o segredo.
+
+Roda na máquina do agente, no mesmo diretório (ou com o mesmo arquivo de
+configuração e as mesmas SECRETS_FILE/SECRETS_KEY_FILE) do servidor.`
+
+func main() {
+	cfg := config.DefaultServer()
+	_, args, err := config.Load("secrets", os.Args[1:], &cfg)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	store := secrets.NewStore(cfg.SecretsFile, cfg.SecretsKeyFile)
+
+	switch {
+	case args[0] == "set" && len(args) >= 2:
+		value, err := readValue(os.Stdin)
+		if err != nil {
+			log.Fatalf("erro ao ler o valor: %v", err)
+		}
+		if err := store.Set(args[1], value, args[2:]...); err != nil {
+			log.Fatalf("erro ao gravar o segredo: %v", err)
+		}
+		fmt.Printf("Segredo %s gravado.\n", args[1])
+
+	case args[0] == "delete" && len(args) == 2:
+		if err := store.Delete(args[1]); err != nil {
+			log.Fatalf("erro ao remover o segredo: %v", err)
+		}
+		fmt.Printf("Segredo %s removido.\n", args[1])
+
+	case args[0] == "list" && len(args) == 1:
+		list, err := store.List()
+		if err != nil {
+			log.Fatalf("erro ao listar segredos: %v", err)
+		}
+		for _, info := range list {
+			if len(info.Bots) == 0 {
+				fmt.Println(info.Name)
+			} else {
+				fmt.Printf("%s\t%s\n", info.Name, strings.Join(info.Bots, " "))
+			}
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// readValue lê tudo da entrada padrão, sem a quebra de linha final que o
+// echo e o terminal acrescentam.
+func readValue(r io.Reader) (string, error) {
+	data, err := io.ReadAll(bufio.NewReader(r))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
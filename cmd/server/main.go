@@ -1,28 +1,103 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net"
+	"orchestrator/internal/auth"
+	"orchestrator/internal/certs"
+	"orchestrator/internal/config"
 	"orchestrator/internal/orchestrator"
 	"orchestrator/pb"
+	"os"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 func main() {
-	lis, err := net.Listen("tcp", ":50051")
+	cfg := config.DefaultServer()
+	loaded, _, err := config.Load("server", os.Args[1:], &cfg)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	loaded.Print(os.Stdout)
+
+	creds, err := serverCredentials(cfg.TLS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lis, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
-	fmt.Println("Server started at :50051")
-	orchestratorService := orchestrator.NewOrchestratorServiceServer()
+	orchestratorService, err := orchestrator.NewOrchestratorServiceServer(cfg)
+	if err != nil {
+		log.Fatalf("failed to start orchestrator: %v", err)
+	}
+	authOpts, err := authOptions(cfg.Auth, orchestratorService.AccessRules())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	grpcServer := grpc.NewServer(append(authOpts, grpc.Creds(creds))...)
+	fmt.Println("Server started at " + cfg.ListenAddr)
 	pb.RegisterOrchestratorServiceServer(grpcServer, orchestratorService)
 
 	if err := grpcServer.Serve(lis); err != nil {
 		panic(err)
 	}
+}
+
+// serverCredentials exige TLS, a menos que tls.insecure esteja ligado. Os
+// certificados são relidos a cada tls.reload_interval.
+func serverCredentials(cfg config.ServerTLS) (credentials.TransportCredentials, error) {
+	if cfg.Insecure {
+		fmt.Println("AVISO: TLS desligado (tls.insecure); qualquer um na rede pode acionar o agente")
+		return insecure.NewCredentials(), nil
+	}
+	if !cfg.Enabled() {
+		return nil, errors.New("TLS não configurado: informe tls.cert_file e tls.key_file (ou tls.insecure, só para desenvolvimento)")
+	}
+	reloader, err := certs.NewReloader(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	go reloader.Watch(context.Background(), cfg.ReloadInterval)
+	if cfg.ClientCAFile != "" {
+		fmt.Println("TLS ligado, com verificação de certificado de cliente (mTLS)")
+	} else {
+		fmt.Println("TLS ligado")
+	}
+	return credentials.NewTLS(reloader.ServerConfig()), nil
+}
 
+// authOptions instala os interceptors de autenticação, a menos que
+// auth.disabled esteja ligado.
+func authOptions(cfg config.Auth, rules map[string]auth.Rule) ([]grpc.ServerOption, error) {
+	if cfg.Disabled {
+		fmt.Println("AVISO: autenticação desligada (auth.disabled); qualquer chamada é aceita")
+		return nil, nil
+	}
+	if !cfg.Enabled() {
+		return nil, errors.New("autenticação não configurada: informe auth.tokens_file ou auth.jwt_key_file (ou auth.disabled, só para desenvolvimento)")
+	}
+	authn, err := auth.NewAuthenticator(cfg.TokensFile, cfg.JWTKeyFile, cfg.JWTIssuer, cfg.JWTAudience)
+	if err != nil {
+		return nil, err
+	}
+	interceptor := auth.NewInterceptor(authn, rules)
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(interceptor.Unary),
+		grpc.StreamInterceptor(interceptor.Stream),
+	}, nil
 }
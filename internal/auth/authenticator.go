@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/metadata"
+)
+
+var ErrUnauthenticated = errors.New("credencial ausente ou inválida")
+
+// staticToken é uma entrada do arquivo de tokens. Só o SHA-256 do token fica
+// no arquivo; gere com: printf %s "$TOKEN" | sha256sum
+type staticToken struct {
+	Subject     string   `json:"subject"`
+	TokenSHA256 string   `json:"token_sha256"`
+	Role        string   `json:"role"`
+	Bots        []string `json:"bots,omitempty"`
+
+	hash     []byte
+	identity *Identity
+}
+
+// Authenticator valida o token Bearer de uma chamada.
+type Authenticator struct {
+	tokens []staticToken
+
+	jwtKey      any
+	jwtMethods  []string
+	jwtIssuer   string
+	jwtAudience string
+}
+
+// NewAuthenticator carrega o arquivo de tokens e a chave dos JWTs; qualquer um
+// dos dois pode ficar vazio, mas não ambos.
+func NewAuthenticator(tokensFile, jwtKeyFile, issuer, audience string) (*Authenticator, error) {
+	if tokensFile == "" && jwtKeyFile == "" {
+		return nil, errors.New("nenhum método de autenticação configurado")
+	}
+	a := &Authenticator{jwtIssuer: issuer, jwtAudience: audience}
+	if tokensFile != "" {
+		tokens, err := loadTokens(tokensFile)
+		if err != nil {
+			return nil, err
+		}
+		a.tokens = tokens
+	}
+	if jwtKeyFile != "" {
+		key, methods, err := loadJWTKey(jwtKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		a.jwtKey, a.jwtMethods = key, methods
+	}
+	return a, nil
+}
+
+func loadTokens(path string) ([]staticToken, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler o arquivo de tokens: %v", err)
+	}
+	var tokens []staticToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("arquivo de tokens inválido: %v", err)
+	}
+	for i := range tokens {
+		t := &tokens[i]
+		if t.Subject == "" {
+			return nil, fmt.Errorf("arquivo de tokens: entrada %d sem subject", i+1)
+		}
+		hash, err := hex.DecodeString(t.TokenSHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("arquivo de tokens: token_sha256 de %s deve ser um SHA-256 em hexadecimal", t.Subject)
+		}
+		role, err := ParseRole(t.Role)
+		if err != nil {
+			return nil, fmt.Errorf("arquivo de tokens: %s: %v", t.Subject, err)
+		}
+		t.hash = hash
+		t.identity = &Identity{Subject: t.Subject, Role: role, Bots: t.Bots}
+	}
+	return tokens, nil
+}
+
+// loadJWTKey aceita uma chave pública PEM (RSA, ECDSA ou Ed25519) ou, para
+// HS256, o próprio segredo compartilhado.
+func loadJWTKey(path string) (any, []string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler a chave dos JWTs: %v", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		secret := []byte(strings.TrimSpace(string(data)))
+		if len(secret) < 32 {
+			return nil, nil, errors.New("segredo HMAC dos JWTs deve ter pelo menos 32 bytes")
+		}
+		return secret, []string{"HS256", "HS384", "HS512"}, nil
+	}
+	if block.Type != "PUBLIC KEY" {
+		return nil, nil, fmt.Errorf("chave dos JWTs: esperado PUBLIC KEY, encontrado %s", block.Type)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("chave dos JWTs inválida: %v", err)
+	}
+	var methods []string
+	switch key.(type) {
+	case *rsa.PublicKey:
+		methods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}
+	case *ecdsa.PublicKey:
+		methods = []string{"ES256", "ES384", "ES512"}
+	case ed25519.PublicKey:
+		methods = []string{"EdDSA"}
+	default:
+		return nil, nil, fmt.Errorf("tipo de chave %T não suportado", key)
+	}
+	return key, methods, nil
+}
+
+// claims são as claims esperadas nos JWTs: sub, role e, opcionalmente, a
+// lista de padrões de bots.
+type claims struct {
+	Role string   `json:"role"`
+	Bots []string `json:"bots,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Authenticate identifica a chamada pelo cabeçalho "authorization: Bearer".
+func (a *Authenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	sum := sha256.Sum256([]byte(token))
+	for _, t := range a.tokens {
+		if subtle.ConstantTimeCompare(sum[:], t.hash) == 1 {
+			return t.identity, nil
+		}
+	}
+	if a.jwtKey != nil && strings.Count(token, ".") == 2 {
+		return a.parseJWT(token)
+	}
+	return nil, ErrUnauthenticated
+}
+
+func (a *Authenticator) parseJWT(token string) (*Identity, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(a.jwtMethods), jwt.WithExpirationRequired()}
+	if a.jwtIssuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.jwtIssuer))
+	}
+	if a.jwtAudience != "" {
+		opts = append(opts, jwt.WithAudience(a.jwtAudience))
+	}
+	var c claims
+	if _, err := jwt.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) { return a.jwtKey, nil }, opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	if c.Subject == "" {
+		return nil, fmt.Errorf("%w: JWT sem sub", ErrUnauthenticated)
+	}
+	role, err := ParseRole(c.Role)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return &Identity{Subject: c.Subject, Role: role, Bots: c.Bots}, nil
+}
+
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		if scheme, token, ok := strings.Cut(value, " "); ok && strings.EqualFold(scheme, "bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc/credentials"
+)
+
+// bearer envia o token em cada chamada ao agente.
+type bearer struct {
+	token      string
+	requireTLS bool
+}
+
+// Bearer devolve as credenciais por chamada do cliente. Com requireTLS, o
+// gRPC se recusa a enviar o token por uma conexão sem TLS.
+func Bearer(token string, requireTLS bool) credentials.PerRPCCredentials {
+	return bearer{token: token, requireTLS: requireTLS}
+}
+
+func (b bearer) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + b.token}, nil
+}
+
+func (b bearer) RequireTransportSecurity() bool {
+	return b.requireTLS
+}
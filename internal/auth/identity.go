@@ -0,0 +1,112 @@
+// Package auth autentica as chamadas ao agente (tokens estáticos ou JWT) e
+// autoriza cada ação pelo papel e pelos bots permitidos à identidade.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Role é o papel de uma identidade. Cada papel inclui as permissões dos
+// anteriores.
+type Role int
+
+const (
+	RoleNone Role = iota
+	// RoleViewer consulta jobs e agendamentos e acompanha logs.
+	RoleViewer
+	// RoleOperator também executa e cancela bots.
+	RoleOperator
+	// RoleAdmin também remove versões, limpa o disco e gerencia
+	// agendamentos.
+	RoleAdmin
+)
+
+var roleNames = map[Role]string{
+	RoleViewer:   "viewer",
+	RoleOperator: "operator",
+	RoleAdmin:    "admin",
+}
+
+func (r Role) String() string {
+	if name, ok := roleNames[r]; ok {
+		return name
+	}
+	return "none"
+}
+
+// ParseRole converte "viewer", "operator" ou "admin".
+func ParseRole(s string) (Role, error) {
+	for role, name := range roleNames {
+		if strings.EqualFold(s, name) {
+			return role, nil
+		}
+	}
+	return RoleNone, fmt.Errorf("papel desconhecido %q (use viewer, operator ou admin)", s)
+}
+
+// Identity é quem fez a chamada.
+type Identity struct {
+	Subject string
+	Role    Role
+	// Bots são os padrões (path.Match) dos bots acessíveis; vazio libera
+	// todos.
+	Bots []string
+}
+
+// AllBots informa se a identidade não tem restrição de bots.
+func (id *Identity) AllBots() bool {
+	if len(id.Bots) == 0 {
+		return true
+	}
+	for _, pattern := range id.Bots {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessBot informa se botID casa com algum dos padrões da identidade.
+func (id *Identity) CanAccessBot(botID string) bool {
+	if id.AllBots() {
+		return true
+	}
+	for _, pattern := range id.Bots {
+		if ok, _ := path.Match(pattern, botID); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// NewContext devolve um contexto que carrega id.
+func NewContext(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext devolve a identidade autenticada da chamada, se houver.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(*Identity)
+	return id, ok
+}
+
+// Subject é o nome de quem fez a chamada, ou vazio com a autenticação
+// desligada.
+func Subject(ctx context.Context) string {
+	if id, ok := FromContext(ctx); ok {
+		return id.Subject
+	}
+	return ""
+}
+
+// CanAccessBot é usado pelas listagens para filtrar o resultado. Sem
+// identidade (autenticação desligada), tudo é visível.
+func CanAccessBot(ctx context.Context, botID string) bool {
+	id, ok := FromContext(ctx)
+	return !ok || id.CanAccessBot(botID)
+}
@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Rule é a exigência de um método do serviço.
+type Rule struct {
+	Role Role
+	// Bot devolve o bot alvo da requisição, ou false quando o alvo não existe
+	// (o handler responde NotFound). Nil quando o método não é de um bot
+	// específico; listagens filtram o resultado com CanAccessBot.
+	Bot func(req any) (string, bool)
+	// AllBots exige uma identidade sem restrição de bots, para ações que
+	// afetam todos eles.
+	AllBots bool
+}
+
+// Interceptor autentica e autoriza as chamadas. Métodos sem regra são
+// negados.
+type Interceptor struct {
+	authn *Authenticator
+	rules map[string]Rule
+}
+
+func NewInterceptor(authn *Authenticator, rules map[string]Rule) *Interceptor {
+	return &Interceptor{authn: authn, rules: rules}
+}
+
+// authenticate identifica a chamada e confere o papel exigido pelo método.
+func (i *Interceptor) authenticate(ctx context.Context, method string) (*Identity, Rule, error) {
+	id, err := i.authn.Authenticate(ctx)
+	if err != nil {
+		return nil, Rule{}, status.Error(codes.Unauthenticated, err.Error())
+	}
+	rule, ok := i.rules[method]
+	if !ok {
+		return nil, Rule{}, i.deny(id, method, "método sem regra de acesso")
+	}
+	if id.Role < rule.Role {
+		return nil, Rule{}, i.deny(id, method, fmt.Sprintf("exige o papel %s", rule.Role))
+	}
+	if rule.AllBots && !id.AllBots() {
+		return nil, Rule{}, i.deny(id, method, "exige acesso a todos os bots")
+	}
+	return id, rule, nil
+}
+
+// authorizeBot confere o bot alvo da requisição.
+func (i *Interceptor) authorizeBot(id *Identity, rule Rule, method string, req any) error {
+	if rule.Bot == nil {
+		return nil
+	}
+	botID, ok := rule.Bot(req)
+	if ok && !id.CanAccessBot(botID) {
+		return i.deny(id, method, fmt.Sprintf("sem acesso ao bot %s", botID))
+	}
+	return nil
+}
+
+func (i *Interceptor) deny(id *Identity, method, reason string) error {
+	fmt.Printf("auth: %s (%s) negado em %s: %s\n", id.Subject, id.Role, method, reason)
+	return status.Errorf(codes.PermissionDenied, "acesso negado: %s", reason)
+}
+
+func (i *Interceptor) Unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	id, rule, err := i.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.authorizeBot(id, rule, info.FullMethod, req); err != nil {
+		return nil, err
+	}
+	return handler(NewContext(ctx, id), req)
+}
+
+func (i *Interceptor) Stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	id, rule, err := i.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{
+		ServerStream: ss,
+		ctx:          NewContext(ss.Context(), id),
+		authorize: func(req any) error {
+			return i.authorizeBot(id, rule, info.FullMethod, req)
+		},
+	})
+}
+
+// authorizedStream confere o bot alvo quando a requisição chega, já que nos
+// streams ela só é lida pelo handler.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	authorize func(req any) error
+	checked   bool
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *authorizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if s.checked {
+		return nil
+	}
+	if err := s.authorize(m); err != nil {
+		return err
+	}
+	s.checked = true
+	return nil
+}
@@ -0,0 +1,214 @@
+// Package certs monta as configurações TLS do agente e do cliente a partir de
+// arquivos PEM e os relê quando mudam, sem reiniciar o processo.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader guarda o par certificado/chave e o pool de CAs carregados dos
+// arquivos e os substitui quando algum arquivo muda. Uma falha ao recarregar
+// mantém os valores anteriores.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu     sync.RWMutex
+	cert   *tls.Certificate
+	pool   *x509.CertPool
+	stamps map[string]time.Time
+}
+
+// NewReloader carrega os arquivos pela primeira vez. certFile e keyFile vêm
+// juntos ou ficam ambos vazios; caFile vazio não carrega nenhum pool.
+func NewReloader(certFile, keyFile, caFile string) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("certificado e chave devem ser informados juntos")
+	}
+	r := &Reloader{certFile: certFile, keyFile: keyFile, caFile: caFile}
+	if _, err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Reloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+// reload relê os arquivos se algum mudou desde a última leitura e informa se
+// houve troca.
+func (r *Reloader) reload() (bool, error) {
+	stamps := make(map[string]time.Time)
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return false, fmt.Errorf("erro ao acessar %s: %v", f, err)
+		}
+		stamps[f] = info.ModTime()
+	}
+	r.mu.RLock()
+	changed := len(stamps) != len(r.stamps)
+	for f, t := range stamps {
+		if !r.stamps[f].Equal(t) {
+			changed = true
+		}
+	}
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return false, fmt.Errorf("erro ao carregar %s e %s: %v", r.certFile, r.keyFile, err)
+		}
+		cert = &c
+	}
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		data, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return false, fmt.Errorf("erro ao ler %s: %v", r.caFile, err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return false, fmt.Errorf("%s não contém certificados PEM", r.caFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.pool, r.stamps = cert, pool, stamps
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Watch confere os arquivos a cada interval até ctx terminar.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed, err := r.reload()
+		if err != nil {
+			fmt.Printf("TLS: erro ao recarregar os certificados, mantendo os anteriores: %v\n", err)
+		} else if changed {
+			fmt.Printf("TLS: certificados recarregados (%s)\n", r.describe())
+		}
+	}
+}
+
+func (r *Reloader) describe() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.cert == nil || r.cert.Leaf == nil {
+		return "sem certificado próprio"
+	}
+	leaf := r.cert.Leaf
+	return fmt.Sprintf("%s, válido até %s", leaf.Subject.CommonName, leaf.NotAfter.Format(time.RFC3339))
+}
+
+func (r *Reloader) current() (*tls.Certificate, *x509.CertPool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, r.pool
+}
+
+// ServerConfig é a configuração do agente. Com um CA carregado, o cliente
+// precisa apresentar um certificado assinado por ele (mTLS).
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert, pool := r.current()
+			if cert == nil {
+				return nil, errors.New("nenhum certificado carregado")
+			}
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*cert},
+				NextProtos:   []string{"h2"},
+			}
+			if pool != nil {
+				cfg.ClientCAs = pool
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// ClientConfig é a configuração do cliente para conectar ao agente em host.
+// Sem CA carregado, o certificado do agente é verificado com as raízes do
+// sistema; serverName substitui host na verificação quando não é vazio.
+func (r *Reloader) ClientConfig(serverName, host string) *tls.Config {
+	// O nome esperado não pode vir do ConnectionState: lá o ServerName é o
+	// do SNI, que fica vazio quando o agente é acessado por IP.
+	expected := serverName
+	if expected == "" {
+		expected = host
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, _ := r.current()
+			if cert == nil {
+				return &tls.Certificate{}, nil
+			}
+			return cert, nil
+		},
+		// A verificação padrão usaria um RootCAs fixo; ela é feita em
+		// VerifyConnection para que o CA recarregado valha nas próximas
+		// conexões.
+		InsecureSkipVerify: true,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			_, pool := r.current()
+			return verifyPeer(cs, pool, expected)
+		},
+	}
+}
+
+// verifyPeer verifica a cadeia do agente e se o certificado vale para name,
+// um nome DNS ou um IP.
+func verifyPeer(cs tls.ConnectionState, roots *x509.CertPool, name string) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("o agente não apresentou certificado")
+	}
+	if name == "" {
+		return errors.New("nome esperado no certificado do agente não informado")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       name,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
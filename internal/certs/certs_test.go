@@ -0,0 +1,154 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	file string
+}
+
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "CA de teste"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "ca.pem")
+	writePEM(t, file, "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, file: file}
+}
+
+// issue emite um certificado de servidor para os nomes e IPs informados e
+// devolve os arquivos do certificado e da chave.
+func (ca *testCA) issue(t *testing.T, dir, name string, dnsNames []string, ips []net.IP) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		DNSNames:     dnsNames,
+		IPAddresses:  ips,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile := filepath.Join(dir, name+".pem")
+	keyFile := filepath.Join(dir, name+"-key.pem")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return certFile, keyFile
+}
+
+func writePEM(t *testing.T, file, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// handshake sobe um agente com certFile/keyFile e conecta a ele por IP com a
+// configuração de cliente de ClientConfig(serverName, host).
+func handshake(t *testing.T, certFile, keyFile, caFile, serverName, host string) error {
+	t.Helper()
+	server, err := NewReloader(certFile, keyFile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", server.ServerConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		conn.(*tls.Conn).Handshake()
+		conn.Close()
+	}()
+
+	client, err := NewReloader("", "", caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := tls.Dial("tcp", ln.Addr().String(), client.ClientConfig(serverName, host))
+	if err != nil {
+		return err
+	}
+	conn.Close()
+	return nil
+}
+
+func TestClientConfigVerifiesIP(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	agentCert, agentKey := ca.issue(t, dir, "agente", nil, []net.IP{net.ParseIP("127.0.0.1")})
+	otherCert, otherKey := ca.issue(t, dir, "outro", nil, []net.IP{net.ParseIP("10.0.0.2")})
+	namedCert, namedKey := ca.issue(t, dir, "nomeado", []string{"agente.interno"}, nil)
+
+	tests := []struct {
+		name       string
+		cert, key  string
+		serverName string
+		wantErr    bool
+	}{
+		{"IP do certificado", agentCert, agentKey, "", false},
+		{"certificado de outro IP", otherCert, otherKey, "", true},
+		{"certificado só com nome DNS", namedCert, namedKey, "", true},
+		{"server_name substitui o IP", namedCert, namedKey, "agente.interno", false},
+		{"server_name diferente", agentCert, agentKey, "agente.interno", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handshake(t, tt.cert, tt.key, ca.file, tt.serverName, "127.0.0.1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("handshake: erro = %v, esperado erro: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
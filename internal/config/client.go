@@ -0,0 +1,82 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client é a configuração do cliente HTTP (cmd/client).
+type Client struct {
+	ListenAddr string `yaml:"listen_addr" toml:"listen_addr" env:"HTTP_LISTEN_ADDR" help:"endereço do servidor HTTP"`
+	AgentHost  string `yaml:"agent_host" toml:"agent_host" env:"GRPC_SERVER_HOST" help:"host do agente gRPC"`
+	AgentPort  int    `yaml:"agent_port" toml:"agent_port" env:"GRPC_SERVER_PORT" help:"porta do agente gRPC"`
+	AgentToken string `yaml:"agent_token" toml:"agent_token" env:"AGENT_TOKEN" secret:"true" help:"token Bearer (estático ou JWT) enviado ao agente"`
+
+	TLS ClientTLS `yaml:"tls" toml:"tls"`
+	Git Git       `yaml:"git" toml:"git"`
+}
+
+// ClientTLS é a conexão do cliente com o agente. O certificado de cliente só
+// é necessário quando o agente exige mTLS.
+type ClientTLS struct {
+	CAFile         string        `yaml:"ca_file" toml:"ca_file" env:"TLS_CA_FILE" help:"CA do certificado do agente (padrão: raízes do sistema)"`
+	CertFile       string        `yaml:"cert_file" toml:"cert_file" env:"TLS_CERT_FILE" help:"certificado de cliente para mTLS (PEM)"`
+	KeyFile        string        `yaml:"key_file" toml:"key_file" env:"TLS_KEY_FILE" help:"chave do certificado de cliente (PEM)"`
+	ServerName     string        `yaml:"server_name" toml:"server_name" env:"TLS_SERVER_NAME" help:"nome esperado no certificado do agente (padrão: agent_host)"`
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval" env:"TLS_RELOAD_INTERVAL" help:"intervalo para reler os certificados (0 desliga)"`
+	Insecure       bool          `yaml:"insecure" toml:"insecure" env:"TLS_INSECURE" help:"conecta ao agente sem TLS; só para desenvolvimento"`
+}
+
+func (t ClientTLS) validate() error {
+	var errs []error
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("tls: cert_file e key_file devem ser informados juntos"))
+	}
+	if t.Insecure && (t.CAFile != "" || t.CertFile != "") {
+		errs = append(errs, fmt.Errorf("tls.insecure: não pode ser usado junto com certificados"))
+	}
+	if t.ReloadInterval < 0 {
+		errs = append(errs, fmt.Errorf("tls.reload_interval: não pode ser negativo"))
+	}
+	return errors.Join(errs...)
+}
+
+// DefaultClient devolve os padrões do cliente.
+func DefaultClient() Client {
+	return Client{
+		ListenAddr: ":8080",
+		AgentHost:  "localhost",
+		AgentPort:  50051,
+		TLS:        ClientTLS{ReloadInterval: 30 * time.Second},
+		Git:        DefaultGit(),
+	}
+}
+
+// AgentAddr é o endereço host:porta do agente.
+func (c *Client) AgentAddr() string {
+	return net.JoinHostPort(c.AgentHost, strconv.Itoa(c.AgentPort))
+}
+
+func (c *Client) Validate() error {
+	var errs []error
+	if err := checkListenAddr(c.ListenAddr); err != nil {
+		errs = append(errs, fmt.Errorf("listen_addr: %v", err))
+	}
+	if strings.TrimSpace(c.AgentHost) == "" || strings.ContainsAny(c.AgentHost, "/ ") {
+		errs = append(errs, fmt.Errorf("agent_host: host inválido %q", c.AgentHost))
+	}
+	if c.AgentPort < 1 || c.AgentPort > 65535 {
+		errs = append(errs, fmt.Errorf("agent_port: porta inválida %d", c.AgentPort))
+	}
+	if err := c.TLS.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Git.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,301 @@
+// Package config carrega a configuração tipada dos binários. Cada valor pode
+// vir, em ordem crescente de precedência, do padrão, de um arquivo YAML ou
+// TOML, de uma variável de ambiente ou de uma flag de linha de comando.
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Validator é implementado pelas configurações tipadas; Validate roda depois
+// que todas as fontes foram aplicadas.
+type Validator interface {
+	Validate() error
+}
+
+// Origens de um valor, mostradas em Print.
+const (
+	sourceDefault = "padrão"
+	sourceFile    = "arquivo"
+	sourceEnv     = "env"
+	sourceFlag    = "flag"
+)
+
+// field é um valor folha da configuração. As tags do campo definem o nome no
+// arquivo (yaml/toml), a variável de ambiente (env), o texto de ajuda (help)
+// e se o valor deve ser mascarado ao imprimir (secret:"true"). A flag tem o
+// nome do caminho no arquivo com "-" no lugar de "." e "_".
+type field struct {
+	path   string
+	env    string
+	help   string
+	secret bool
+	value  reflect.Value
+	source string
+}
+
+func (f *field) flagName() string {
+	return strings.NewReplacer(".", "-", "_", "-").Replace(f.path)
+}
+
+// Loaded é o resultado de Load: lembra de onde veio cada valor.
+type Loaded struct {
+	file   string
+	fields []*field
+}
+
+// Load preenche cfg, que já deve conter os valores padrão, e o valida. O
+// arquivo de configuração é indicado por -config ou CONFIG_FILE; a extensão
+// .toml escolhe o formato TOML e qualquer outra, YAML. Devolve os argumentos
+// que sobram depois das flags.
+func Load(name string, args []string, cfg Validator) (*Loaded, []string, error) {
+	loaded := &Loaded{fields: collectFields(reflect.ValueOf(cfg).Elem(), "")}
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "arquivo de configuração YAML ou TOML (env CONFIG_FILE)")
+	flagValues := make(map[string]*flagValue)
+	for _, f := range loaded.fields {
+		v := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		flagValues[f.flagName()] = v
+		usage := f.help
+		if f.env != "" {
+			usage += " (env " + f.env + ")"
+		}
+		fs.Var(v, f.flagName(), usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	for _, f := range loaded.fields {
+		f.source = sourceDefault
+	}
+	if *configFile != "" {
+		loaded.file = *configFile
+		if err := loaded.loadFile(cfg, *configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var errs []error
+	for _, f := range loaded.fields {
+		if f.env == "" {
+			continue
+		}
+		if v := os.Getenv(f.env); v != "" {
+			if err := setString(f.value, v); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", f.env, err))
+				continue
+			}
+			f.source = sourceEnv + " " + f.env
+		}
+	}
+	for _, f := range loaded.fields {
+		v := flagValues[f.flagName()]
+		if !v.set {
+			continue
+		}
+		if err := setString(f.value, v.raw); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %v", f.flagName(), err))
+			continue
+		}
+		f.source = sourceFlag + " -" + f.flagName()
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("configuração inválida: %w", err)
+	}
+	return loaded, fs.Args(), nil
+}
+
+// loadFile aplica o arquivo sobre os padrões. Chaves desconhecidas são erro,
+// para que um erro de digitação não passe despercebido.
+func (l *Loaded) loadFile(cfg Validator, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("erro ao ler o arquivo de configuração: %v", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		md, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("%s: chave desconhecida %s", path, undecoded[0])
+		}
+		for _, f := range l.fields {
+			if md.IsDefined(strings.Split(f.path, ".")...) {
+				f.source = sourceFile
+			}
+		}
+		return nil
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && err != io.EOF {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	var keys map[string]any
+	yaml.Unmarshal(data, &keys)
+	for _, f := range l.fields {
+		if yamlDefined(keys, strings.Split(f.path, ".")) {
+			f.source = sourceFile
+		}
+	}
+	return nil
+}
+
+func yamlDefined(keys map[string]any, path []string) bool {
+	v, ok := keys[path[0]]
+	if !ok || len(path) == 1 {
+		return ok
+	}
+	sub, ok := v.(map[string]any)
+	return ok && yamlDefined(sub, path[1:])
+}
+
+// Print mostra a configuração efetiva e a origem de cada valor, com os
+// segredos mascarados.
+func (l *Loaded) Print(w io.Writer) {
+	if l.file != "" {
+		fmt.Fprintf(w, "Configuração efetiva (arquivo %s):\n", l.file)
+	} else {
+		fmt.Fprintln(w, "Configuração efetiva:")
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, f := range l.fields {
+		value := formatValue(f.value)
+		if f.secret && value != "" {
+			value = "***"
+		}
+		fmt.Fprintf(tw, "  %s\t= %s\t[%s]\n", f.path, value, f.source)
+	}
+	tw.Flush()
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// collectFields percorre a struct de configuração; structs aninhadas viram
+// seções no arquivo.
+func collectFields(v reflect.Value, prefix string) []*field {
+	var fields []*field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Type.Kind() == reflect.Struct && !reflect.PointerTo(sf.Type).Implements(textUnmarshalerType) {
+			fields = append(fields, collectFields(fv, prefix+name+".")...)
+			continue
+		}
+		fields = append(fields, &field{
+			path:   prefix + name,
+			env:    sf.Tag.Get("env"),
+			help:   sf.Tag.Get("help"),
+			secret: sf.Tag.Get("secret") == "true",
+			value:  fv,
+		})
+	}
+	return fields
+}
+
+// setString converte o texto de uma variável de ambiente ou flag para o tipo
+// do campo. Listas são separadas por vírgula.
+func setString(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("duração inválida %q", s)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("booleano inválido %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return fmt.Errorf("inteiro inválido %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("número inválido %q", s)
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("tipo %s não suportado", v.Type())
+	}
+	return nil
+}
+
+func formatValue(v reflect.Value) string {
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	if v.Kind() == reflect.Slice {
+		return strings.Join(v.Interface().([]string), ",")
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// flagValue guarda o texto da flag para ser aplicado depois do arquivo e do
+// ambiente, respeitando a precedência.
+type flagValue struct {
+	raw    string
+	set    bool
+	isBool bool
+}
+
+func (v *flagValue) String() string { return v.raw }
+
+func (v *flagValue) Set(s string) error {
+	v.raw, v.set = s, true
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
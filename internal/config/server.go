@@ -0,0 +1,241 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Server é a configuração do agente (cmd/server). Os nomes das variáveis de
+// ambiente são os mesmos de antes do arquivo de configuração existir.
+type Server struct {
+	ListenAddr        string        `yaml:"listen_addr" toml:"listen_addr" env:"LISTEN_ADDR" help:"endereço do servidor gRPC"`
+	BotsDir           string        `yaml:"bots_dir" toml:"bots_dir" env:"BOTS_DIR" help:"diretório com os clones e os logs dos bots"`
+	CacheDir          string        `yaml:"cache_dir" toml:"cache_dir" env:"CACHE_DIR" help:"diretório dos ambientes virtuais em cache e do cache do pip"`
+	SchedulesFile     string        `yaml:"schedules_file" toml:"schedules_file" env:"SCHEDULES_FILE" help:"arquivo dos agendamentos"`
+	CredentialsFile   string        `yaml:"credentials_file" toml:"credentials_file" env:"GIT_CREDENTIALS_FILE" help:"arquivo das credenciais git"`
+	SecretsFile       string        `yaml:"secrets_file" toml:"secrets_file" env:"SECRETS_FILE" help:"arquivo dos segredos cifrados"`
+	SecretsKeyFile    string        `yaml:"secrets_key_file" toml:"secrets_key_file" env:"SECRETS_KEY_FILE" help:"chave dos segredos"`
+	PythonInterpreter string        `yaml:"python_interpreter" toml:"python_interpreter" env:"PYTHON_INTERPRETER" help:"interpretador base usado para criar os venvs (padrão: python3 do PATH)"`
+	MaxConcurrentRuns int           `yaml:"max_concurrent_runs" toml:"max_concurrent_runs" env:"MAX_CONCURRENT_RUNS" help:"execuções simultâneas"`
+	CloneTimeout      time.Duration `yaml:"clone_timeout" toml:"clone_timeout" env:"CLONE_TIMEOUT" help:"tempo limite do clone"`
+	BotTimeout        time.Duration `yaml:"bot_timeout" toml:"bot_timeout" env:"BOT_TIMEOUT" help:"tempo limite padrão dos bots (0 desliga)"`
+	InstallTimeout    time.Duration `yaml:"install_timeout" toml:"install_timeout" env:"INSTALL_TIMEOUT" help:"tempo limite da instalação de dependências (0 desliga)"`
+	LogBufferLines    int           `yaml:"log_buffer_lines" toml:"log_buffer_lines" env:"LOG_BUFFER_LINES" help:"linhas de log por job mantidas em memória para AttachLogs; o arquivo do job guarda todas"`
+	LogMaxLineBytes   ByteSize      `yaml:"log_max_line_bytes" toml:"log_max_line_bytes" env:"LOG_MAX_LINE_BYTES" help:"tamanho máximo de uma linha de saída; o excesso é descartado com um aviso"`
+	LogPartialFlush   time.Duration `yaml:"log_partial_flush" toml:"log_partial_flush" env:"LOG_PARTIAL_FLUSH" help:"tempo sem saída após o qual uma linha sem quebra é enviada; também limita a frequência das atualizações de progresso"`
+	JobHistory        int           `yaml:"job_history" toml:"job_history" env:"JOB_HISTORY" help:"jobs finalizados mantidos em memória para GetJob e ListJobs; os logs dos demais continuam em GetJobLogs"`
+	JobHistoryTTL     time.Duration `yaml:"job_history_ttl" toml:"job_history_ttl" env:"JOB_HISTORY_TTL" help:"tempo que um job finalizado fica em memória (0 desliga)"`
+
+	TLS       ServerTLS `yaml:"tls" toml:"tls"`
+	Auth      Auth      `yaml:"auth" toml:"auth"`
+	Git       Git       `yaml:"git" toml:"git"`
+	Limits    Limits    `yaml:"limits" toml:"limits"`
+	Retention Retention `yaml:"retention" toml:"retention"`
+}
+
+// ServerTLS protege o gRPC do agente. Sem certificado, o agente só sobe com
+// Insecure ligado.
+type ServerTLS struct {
+	CertFile       string        `yaml:"cert_file" toml:"cert_file" env:"TLS_CERT_FILE" help:"certificado do agente (PEM)"`
+	KeyFile        string        `yaml:"key_file" toml:"key_file" env:"TLS_KEY_FILE" help:"chave do certificado do agente (PEM)"`
+	ClientCAFile   string        `yaml:"client_ca_file" toml:"client_ca_file" env:"TLS_CLIENT_CA_FILE" help:"CA dos certificados de cliente; quando definido, exige mTLS"`
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval" env:"TLS_RELOAD_INTERVAL" help:"intervalo para reler os certificados (0 desliga)"`
+	Insecure       bool          `yaml:"insecure" toml:"insecure" env:"TLS_INSECURE" help:"aceita conexões sem TLS; só para desenvolvimento"`
+}
+
+// Enabled informa se há certificado configurado.
+func (t ServerTLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+func (t ServerTLS) validate() error {
+	var errs []error
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("tls: cert_file e key_file devem ser informados juntos"))
+	}
+	if t.ClientCAFile != "" && !t.Enabled() {
+		errs = append(errs, fmt.Errorf("tls.client_ca_file: exige cert_file e key_file"))
+	}
+	if t.Insecure && t.Enabled() {
+		errs = append(errs, fmt.Errorf("tls.insecure: não pode ser usado junto com um certificado"))
+	}
+	if t.ReloadInterval < 0 {
+		errs = append(errs, fmt.Errorf("tls.reload_interval: não pode ser negativo"))
+	}
+	return errors.Join(errs...)
+}
+
+// Auth define como o agente autentica as chamadas. Sem tokens nem chave de
+// JWT, o agente só sobe com Disabled ligado.
+type Auth struct {
+	TokensFile  string `yaml:"tokens_file" toml:"tokens_file" env:"AUTH_TOKENS_FILE" help:"arquivo JSON com os tokens estáticos (subject, token_sha256, role, bots)"`
+	JWTKeyFile  string `yaml:"jwt_key_file" toml:"jwt_key_file" env:"AUTH_JWT_KEY_FILE" help:"chave pública PEM ou segredo HMAC para validar JWTs"`
+	JWTIssuer   string `yaml:"jwt_issuer" toml:"jwt_issuer" env:"AUTH_JWT_ISSUER" help:"iss exigido nos JWTs (opcional)"`
+	JWTAudience string `yaml:"jwt_audience" toml:"jwt_audience" env:"AUTH_JWT_AUDIENCE" help:"aud exigido nos JWTs (opcional)"`
+	Disabled    bool   `yaml:"disabled" toml:"disabled" env:"AUTH_DISABLED" help:"aceita chamadas sem autenticação; só para desenvolvimento"`
+}
+
+// Enabled informa se há algum método de autenticação configurado.
+func (a Auth) Enabled() bool {
+	return a.TokensFile != "" || a.JWTKeyFile != ""
+}
+
+func (a Auth) validate() error {
+	if a.Disabled && a.Enabled() {
+		return fmt.Errorf("auth.disabled: não pode ser usado junto com tokens_file ou jwt_key_file")
+	}
+	if !a.Enabled() && (a.JWTIssuer != "" || a.JWTAudience != "") {
+		return fmt.Errorf("auth: jwt_issuer e jwt_audience exigem jwt_key_file")
+	}
+	return nil
+}
+
+// Git é a política de repositórios aceitos, comum ao agente e ao cliente.
+type Git struct {
+	AllowedSchemes []string `yaml:"allowed_schemes" toml:"allowed_schemes" env:"ALLOWED_GIT_SCHEMES" help:"esquemas aceitos: https, ssh, http, file"`
+	AllowedHosts   []string `yaml:"allowed_hosts" toml:"allowed_hosts" env:"ALLOWED_GIT_HOSTS" help:"hosts aceitos; \"*\" libera todos e \"*.empresa.com\" os subdomínios"`
+}
+
+// Limits são os limites de recursos padrão dos bots. Zero desliga.
+type Limits struct {
+	Memory       ByteSize `yaml:"memory" toml:"memory" env:"BOT_MEMORY_LIMIT" help:"memória por bot, ex: 512M"`
+	CPU          float64  `yaml:"cpu" toml:"cpu" env:"BOT_CPU_LIMIT" help:"núcleos por bot, ex: 1.5"`
+	Processes    int64    `yaml:"processes" toml:"processes" env:"BOT_MAX_PROCESSES" help:"processos por bot"`
+	OpenFiles    int64    `yaml:"open_files" toml:"open_files" env:"BOT_MAX_OPEN_FILES" help:"arquivos abertos por bot"`
+	CgroupParent string   `yaml:"cgroup_parent" toml:"cgroup_parent" env:"BOT_CGROUP_PARENT" help:"cgroup v2 delegado onde os bots são criados"`
+}
+
+// Retention é a política de limpeza de disco. Zero desliga cada regra.
+type Retention struct {
+	KeepVersions    int           `yaml:"keep_versions" toml:"keep_versions" env:"RETENTION_KEEP_VERSIONS" help:"versões mantidas por bot"`
+	MaxSize         ByteSize      `yaml:"max_size" toml:"max_size" env:"RETENTION_MAX_SIZE" help:"tamanho máximo de bots e cache, ex: 20G"`
+	TTL             time.Duration `yaml:"ttl" toml:"ttl" env:"RETENTION_TTL" help:"tempo máximo desde a última execução, ex: 720h"`
+	JanitorInterval time.Duration `yaml:"janitor_interval" toml:"janitor_interval" env:"JANITOR_INTERVAL" help:"intervalo da limpeza automática (0 desliga)"`
+}
+
+// DefaultServer devolve os padrões do agente.
+func DefaultServer() Server {
+	return Server{
+		ListenAddr:        ":50051",
+		BotsDir:           "./bots",
+		CacheDir:          "./cache",
+		SchedulesFile:     "./data/schedules.json",
+		CredentialsFile:   "./data/credentials.json",
+		SecretsFile:       "./data/secrets.json",
+		SecretsKeyFile:    "./data/secrets.key",
+		MaxConcurrentRuns: runtime.NumCPU(),
+		CloneTimeout:      10 * time.Minute,
+		BotTimeout:        time.Hour,
+		InstallTimeout:    30 * time.Minute,
+		LogBufferLines:    10000,
+		LogMaxLineBytes:   64 * 1024,
+		LogPartialFlush:   time.Second,
+		JobHistory:        1000,
+		JobHistoryTTL:     24 * time.Hour,
+		TLS:               ServerTLS{ReloadInterval: 30 * time.Second},
+		Git:               DefaultGit(),
+		Retention:         Retention{JanitorInterval: time.Hour},
+	}
+}
+
+// DefaultGit aceita https e ssh nos principais serviços de hospedagem.
+func DefaultGit() Git {
+	return Git{
+		AllowedSchemes: []string{"https", "ssh"},
+		AllowedHosts:   []string{"github.com", "gitlab.com", "bitbucket.org"},
+	}
+}
+
+func (c *Server) Validate() error {
+	var errs []error
+	add := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	if err := checkListenAddr(c.ListenAddr); err != nil {
+		add("listen_addr: %v", err)
+	}
+	for name, path := range map[string]string{
+		"bots_dir":         c.BotsDir,
+		"cache_dir":        c.CacheDir,
+		"schedules_file":   c.SchedulesFile,
+		"credentials_file": c.CredentialsFile,
+		"secrets_file":     c.SecretsFile,
+		"secrets_key_file": c.SecretsKeyFile,
+	} {
+		if strings.TrimSpace(path) == "" {
+			add("%s: é obrigatório", name)
+		}
+	}
+	if c.MaxConcurrentRuns < 1 {
+		add("max_concurrent_runs: deve ser pelo menos 1")
+	}
+	if c.LogBufferLines < 1 {
+		add("log_buffer_lines: deve ser pelo menos 1")
+	}
+	if c.LogMaxLineBytes < 1024 {
+		add("log_max_line_bytes: deve ser pelo menos 1K")
+	}
+	if c.LogPartialFlush <= 0 {
+		add("log_partial_flush: deve ser positivo")
+	}
+	if c.JobHistory < 1 {
+		add("job_history: deve ser pelo menos 1")
+	}
+	if c.JobHistoryTTL < 0 {
+		add("job_history_ttl: não pode ser negativo")
+	}
+	if c.CloneTimeout <= 0 {
+		add("clone_timeout: deve ser positivo")
+	}
+	if c.BotTimeout < 0 || c.InstallTimeout < 0 {
+		add("bot_timeout e install_timeout não podem ser negativos")
+	}
+	if err := c.TLS.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Auth.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.Git.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if c.Limits.CPU < 0 || c.Limits.Processes < 0 || c.Limits.OpenFiles < 0 {
+		add("limits: valores não podem ser negativos")
+	}
+	if c.Retention.KeepVersions < 0 || c.Retention.TTL < 0 || c.Retention.JanitorInterval < 0 {
+		add("retention: valores não podem ser negativos")
+	}
+	return errors.Join(errs...)
+}
+
+func (g Git) validate() error {
+	var errs []error
+	needsHost := false
+	for _, scheme := range g.AllowedSchemes {
+		switch strings.ToLower(scheme) {
+		case "https", "ssh", "http":
+			needsHost = true
+		case "file":
+		default:
+			errs = append(errs, fmt.Errorf("git.allowed_schemes: esquema desconhecido %q", scheme))
+		}
+	}
+	if len(g.AllowedSchemes) == 0 {
+		errs = append(errs, fmt.Errorf("git.allowed_schemes: informe pelo menos um esquema"))
+	}
+	if needsHost && len(g.AllowedHosts) == 0 {
+		errs = append(errs, fmt.Errorf("git.allowed_hosts: informe pelo menos um host"))
+	}
+	return errors.Join(errs...)
+}
+
+func checkListenAddr(addr string) error {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		return fmt.Errorf("endereço inválido %q (use host:porta ou :porta)", addr)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ByteSize é um tamanho em bytes escrito como 512M, 2G ou 1GiB. Zero
+// desliga o limite correspondente.
+type ByteSize int64
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	if strings.TrimSpace(string(text)) == "0" {
+		*b = 0
+		return nil
+	}
+	n, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(n)
+	return nil
+}
+
+func (b ByteSize) String() string {
+	if b == 0 {
+		return "0"
+	}
+	return FormatBytes(int64(b))
+}
+
+// ParseByteSize aceita bytes ou um sufixo binário: 512M, 2G, 1GiB, 256Mi.
+func ParseByteSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	units := []struct {
+		suffix string
+		size   int64
+	}{
+		{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10},
+		{"Gi", 1 << 30}, {"Mi", 1 << 20}, {"Ki", 1 << 10},
+		{"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	}
+	multiplier := int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(s, unit.suffix) {
+			s, multiplier = strings.TrimSuffix(s, unit.suffix), unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("tamanho inválido %q", s)
+	}
+	return n * multiplier, nil
+}
+
+// FormatBytes escreve n na maior unidade que o represente bem.
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<30 && n%(1<<30) == 0:
+		return fmt.Sprintf("%dGiB", n>>30)
+	case n >= 1<<20:
+		return fmt.Sprintf("%dMiB", n>>20)
+	}
+	return fmt.Sprintf("%dB", n)
+}
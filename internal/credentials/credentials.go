@@ -0,0 +1,133 @@
+// Package credentials lê as credenciais Git nomeadas que ficam no agente.
+// Os DeployRequests só referenciam o nome; o segredo nunca trafega pela API.
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"orchestrator/internal/validation"
+	"os"
+	"runtime"
+)
+
+// Type é o tipo de uma credencial.
+type Type string
+
+const (
+	// HTTPSToken autentica clones https:// com usuário e token (ex: PAT do
+	// GitHub ou token de deploy do GitLab).
+	HTTPSToken Type = "https_token"
+	// SSHKey autentica clones via ssh com uma deploy key.
+	SSHKey Type = "ssh_key"
+)
+
+var (
+	ErrCredentialNotFound   = errors.New("credencial não encontrada")
+	ErrCredentialNotAllowed = errors.New("credencial não liberada para o bot")
+)
+
+// Credential é uma entrada do arquivo de credenciais, ex:
+//
+//	{
+//	  "github-bots": {"type": "https_token", "username": "x-access-token", "token": "ghp_...", "bots": ["relatorio-*"]},
+//	  "deploy-key": {"type": "ssh_key", "private_key_file": "/etc/gobot/id_ed25519"}
+//	}
+type Credential struct {
+	Name string `json:"-"`
+	Type Type   `json:"type"`
+	// Bots são os padrões (path.Match) dos bots que podem usar a credencial;
+	// vazio libera todos.
+	Bots []string `json:"bots,omitempty"`
+
+	// https_token
+	Username string `json:"username,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	// ssh_key: a chave pode vir inline ou de um arquivo.
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyFile string `json:"private_key_file,omitempty"`
+	// KnownHostsFile, se vazio, faz o ssh aceitar a chave do host no
+	// primeiro acesso (StrictHostKeyChecking=accept-new).
+	KnownHostsFile string `json:"known_hosts_file,omitempty"`
+}
+
+// Secrets devolve os valores que nunca podem aparecer em logs.
+func (c Credential) Secrets() []string {
+	var secrets []string
+	for _, s := range []string{c.Token, c.PrivateKey} {
+		if s != "" {
+			secrets = append(secrets, s)
+		}
+	}
+	return secrets
+}
+
+func (c Credential) validate() error {
+	switch c.Type {
+	case HTTPSToken:
+		if c.Token == "" {
+			return fmt.Errorf("token vazio")
+		}
+	case SSHKey:
+		if (c.PrivateKey == "") == (c.PrivateKeyFile == "") {
+			return fmt.Errorf("informe private_key ou private_key_file")
+		}
+	default:
+		return fmt.Errorf("tipo inválido %q", c.Type)
+	}
+	for _, pattern := range c.Bots {
+		if err := validation.BotPattern(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Store lê as credenciais de um arquivo JSON. O arquivo é relido a cada
+// consulta, então novas credenciais valem sem reiniciar o agente.
+type Store struct {
+	path string
+}
+
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Get devolve a credencial name para uso pelo bot botID. Um arquivo
+// inexistente equivale a um arquivo sem credenciais.
+func (s *Store) Get(name, botID string) (Credential, error) {
+	info, err := os.Stat(s.path)
+	if os.IsNotExist(err) {
+		return Credential{}, fmt.Errorf("%w: %q", ErrCredentialNotFound, name)
+	}
+	if err != nil {
+		return Credential{}, fmt.Errorf("erro ao ler credenciais: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		log.Printf("[credentials] aviso: %s pode ser lido por outros usuários (permissões %v)", s.path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return Credential{}, fmt.Errorf("erro ao ler credenciais: %v", err)
+	}
+	var all map[string]Credential
+	if err := json.Unmarshal(data, &all); err != nil {
+		return Credential{}, fmt.Errorf("arquivo de credenciais inválido: %v", err)
+	}
+
+	cred, ok := all[name]
+	if !ok {
+		return Credential{}, fmt.Errorf("%w: %q", ErrCredentialNotFound, name)
+	}
+	if err := cred.validate(); err != nil {
+		return Credential{}, fmt.Errorf("credencial %q inválida: %v", name, err)
+	}
+	if !validation.MatchBots(cred.Bots, botID) {
+		return Credential{}, fmt.Errorf("%w: %q não pode ser usada por %s", ErrCredentialNotAllowed, name, botID)
+	}
+	cred.Name = name
+	return cred, nil
+}
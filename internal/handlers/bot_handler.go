@@ -1,35 +1,39 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
+	"orchestrator/internal/templates"
+	"orchestrator/internal/validation"
 	"orchestrator/pb"
 	"orchestrator/structs"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type BotHandler struct {
 	AgentClient pb.OrchestratorServiceClient
+	Validation  validation.Policy
 }
 
-func NewBotHandler(agentClient pb.OrchestratorServiceClient) *BotHandler {
+func NewBotHandler(agentClient pb.OrchestratorServiceClient, policy validation.Policy) *BotHandler {
 	return &BotHandler{
 		AgentClient: agentClient,
+		Validation:  policy,
 	}
 }
 
 func (h *BotHandler) RunBotHandler(w http.ResponseWriter, r *http.Request) {
-
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("X-Accel-Buffering", "no")
-	w.Header().Set("Transfer-Encoding", "chunked")
-
-	flusher, ok := w.(http.Flusher)
+	flusher, ok := startStreaming(w)
 	if !ok {
 		http.Error(w, "Streaming não suportado", http.StatusInternalServerError)
 		return
@@ -41,47 +45,282 @@ func (h *BotHandler) RunBotHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req := &pb.DeployRequest{
+		BotId:          bot.BotID,
+		GitRepo:        bot.GitRepo,
+		Version:        bot.Version,
+		ForceReclone:   bot.ForceReclone,
+		Credential:     bot.Credential,
+		Env:            bot.Env,
+		SecretRefs:     bot.SecretRefs,
+		TimeoutSeconds: bot.TimeoutSeconds,
+	}
+	if err := h.Validation.DeployRequest(req, ""); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		writeViolations(w, validation.Violations(err))
+		return
+	}
+
 	flusher.Flush()
 
-	ctx := context.Background()
-	stream, err := h.AgentClient.ExecuteDeploy(ctx, &pb.DeployRequest{
-		BotId:   bot.BotID,
-		GitRepo: bot.GitRepo,
-		Version: bot.Version,
-	})
+	// O job roda desacoplado no agente: se o navegador fechar, apenas este
+	// stream termina e a saída pode ser recuperada em /jobs/{id}/attach.
+	stream, err := h.AgentClient.ExecuteDeploy(r.Context(), req)
 	if err != nil {
 		http.Error(w, "Failed to start bot deployment: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	streamLogs(w, flusher, stream, bot.BotID)
+}
+
+func (h *BotHandler) AttachLogsHandler(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := startStreaming(w)
+	if !ok {
+		http.Error(w, "Streaming não suportado", http.StatusInternalServerError)
+		return
+	}
+
+	jobID := r.PathValue("id")
+	from, _ := strconv.ParseInt(r.URL.Query().Get("from"), 10, 64)
+
+	stream, err := h.AgentClient.AttachLogs(r.Context(), &pb.AttachLogsRequest{
+		JobId:      jobID,
+		FromOffset: from,
+	})
+	if err != nil {
+		http.Error(w, "Failed to attach to job: "+err.Error(), httpStatusFromGRPC(err))
+		return
+	}
+
+	streamLogs(w, flusher, stream, jobID)
+}
+
+func startStreaming(w http.ResponseWriter) (http.Flusher, bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	flusher, ok := w.(http.Flusher)
+	return flusher, ok
+}
+
+// streamLogs repassa cada LogResponse do agente como um fragmento HTML até o
+// stream terminar.
+func streamLogs(w io.Writer, flusher http.Flusher, stream grpc.ServerStreamingClient[pb.LogResponse], label string) {
+	jobAnnounced, summarized := false, false
 	for {
 		logMsg, err := stream.Recv()
 		if err == io.EOF {
-			fmt.Fprintf(w, "<div class='text-green-400 font-bold'>✓ Execução finalizada!</div>")
+			fmt.Fprintf(w, "<div class='text-green-400 font-bold'>✓ Execução finalizada!</div>\n")
+			flusher.Flush()
+			break
+		}
+		if status.Code(err) == codes.Canceled {
+			fmt.Fprintf(w, "<div class='text-yellow-400 font-bold'>⏹ Execução cancelada.</div>\n")
+			flusher.Flush()
+			break
+		}
+		if violations := validation.Violations(err); len(violations) > 0 {
+			writeViolations(w, violations)
 			flusher.Flush()
 			break
 		}
 		if err != nil {
 			log.Printf("Erro no streaming: %v", err)
-			fmt.Fprintf(w, "<div class='text-red-400'>[ERROR] %v</div>", err)
+			if st, ok := status.FromError(err); ok && summarized {
+				// O resumo já mostrou o que aconteceu; fica só o motivo.
+				fmt.Fprintf(w, "<div class='text-red-400 font-bold'>✗ Execução falhou (%s)</div>\n", st.Code())
+			} else {
+				fmt.Fprintf(w, "<div class='text-red-400'>[ERROR] %s</div>\n", html.EscapeString(err.Error()))
+			}
 			flusher.Flush()
 			break
 		}
 
-		colorClass := "text-gray-300"
-		switch logMsg.Status {
-		case "SUCCESS":
-			colorClass = "text-green-400"
-		case "ERROR":
-			colorClass = "text-red-400"
-		case "INFO":
-			colorClass = "text-blue-300"
+		if !jobAnnounced && logMsg.JobId != "" {
+			fmt.Fprintf(w, "<div class='hidden' data-job-id='%s'></div>\n", logMsg.JobId)
+			jobAnnounced = true
 		}
 
-		fmt.Fprintf(w, "<div class='%s'>[%s] %s</div>",
-			colorClass, logMsg.Status, logMsg.Line)
+		if logMsg.GetSummary() != nil {
+			summarized = true
+			writeSummary(w, logMsg)
+			flusher.Flush()
+			fmt.Printf("[%s] RESUMO: %s\n", label, logMsg.Line)
+			continue
+		}
+
+		statusLabel := logStatusName(logMsg.Status)
+		title := fmt.Sprintf("%s %s/%s", logMsg.Timestamp.AsTime().Local().Format("15:04:05.000"),
+			logPhaseName(logMsg.Phase), logSourceName(logMsg.Source))
+		templates.LogLine(logMsg, title).Render(stream.Context(), w)
+		io.WriteString(w, "\n")
 		flusher.Flush()
 
-		fmt.Printf("[%s] %s: %s\n", bot.BotID, logMsg.Status, logMsg.Line)
+		fmt.Printf("[%s] %s: %s\n", label, statusLabel, logMsg.Line)
+	}
+}
+
+// writeViolations mostra um erro de validação por campo no container de logs.
+func writeViolations(w io.Writer, violations []validation.FieldViolation) {
+	for _, v := range violations {
+		fmt.Fprintf(w, "<div class='text-red-400'>[INVÁLIDO] %s: %s</div>\n",
+			html.EscapeString(v.Field), html.EscapeString(v.Description))
+	}
+}
+
+func (h *BotHandler) ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	resp, err := h.AgentClient.ListJobs(r.Context(), &pb.ListJobsRequest{
+		BotId: r.URL.Query().Get("bot_id"),
+		Limit: int32(limit),
+	})
+	if err != nil {
+		http.Error(w, "Failed to list jobs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	templates.Layout(templates.JobList(resp.Jobs)).Render(r.Context(), w)
+}
+
+func (h *BotHandler) CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, err := h.AgentClient.CancelJob(r.Context(), &pb.CancelJobRequest{JobId: r.PathValue("id")})
+	if err != nil {
+		http.Error(w, "Failed to cancel job: "+err.Error(), httpStatusFromGRPC(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"job_id": job.JobId,
+		"state":  job.State.String(),
+	})
+}
+
+type jobLogRecord struct {
+	Time     time.Time `json:"time"`
+	Sequence int64     `json:"seq"`
+	Phase    string    `json:"phase"`
+	Stream   string    `json:"stream"`
+	Status   string    `json:"status"`
+	Line     string    `json:"line"`
+}
+
+// JobLogsHandler devolve o log persistido do job em texto puro ou, com
+// ?format=ndjson, uma linha JSON por mensagem.
+func (h *BotHandler) JobLogsHandler(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("id")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "text"
+	}
+	if format != "text" && format != "ndjson" {
+		http.Error(w, "Invalid format: use text or ndjson", http.StatusBadRequest)
+		return
+	}
+
+	stream, err := h.AgentClient.GetJobLogs(r.Context(), &pb.GetJobLogsRequest{JobId: jobID})
+	if err != nil {
+		http.Error(w, "Failed to get job logs: "+err.Error(), httpStatusFromGRPC(err))
+		return
+	}
+
+	// O primeiro Recv revela se o job existe antes de enviar os headers.
+	entry, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		http.Error(w, "Failed to get job logs: "+err.Error(), httpStatusFromGRPC(err))
+		return
+	}
+
+	if format == "ndjson" {
+		w.Header().Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"job-%s.ndjson\"", jobID))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=\"job-%s.log\"", jobID))
+	}
+
+	enc := json.NewEncoder(w)
+	for err == nil {
+		if format == "ndjson" {
+			enc.Encode(jobLogRecord{
+				Time:     entry.Time.AsTime(),
+				Sequence: entry.Sequence,
+				Phase:    logPhaseName(entry.Phase),
+				Stream:   logSourceName(entry.Source),
+				Status:   logStatusName(entry.Status),
+				Line:     entry.Line,
+			})
+		} else {
+			fmt.Fprintf(w, "%s [%s/%s] %s %s\n",
+				entry.Time.AsTime().Format(time.RFC3339Nano), logPhaseName(entry.Phase), logSourceName(entry.Source),
+				logStatusName(entry.Status), entry.Line)
+		}
+		entry, err = stream.Recv()
+	}
+	if err != io.EOF {
+		log.Printf("Erro ao ler logs do job %s: %v", jobID, err)
+	}
+}
+
+// writeSummary mostra o resumo final do job.
+func writeSummary(w io.Writer, logMsg *pb.LogResponse) {
+	summary := logMsg.GetSummary()
+	colorClass := "text-red-400"
+	switch summary.State {
+	case pb.JobState_JOB_STATE_SUCCEEDED:
+		colorClass = "text-green-400"
+	case pb.JobState_JOB_STATE_CANCELLED:
+		colorClass = "text-yellow-400"
+	}
+	fmt.Fprintf(w, "<div class='%s font-bold border-t border-gray-700 mt-1 pt-1' data-seq='%d' data-failure='%s'>■ %s</div>\n",
+		colorClass, logMsg.Sequence, strings.TrimPrefix(summary.FailureReason.String(), "FAILURE_REASON_"),
+		html.EscapeString(logMsg.Line))
+	if summary.Error != "" {
+		fmt.Fprintf(w, "<div class='%s'>%s</div>\n", colorClass, html.EscapeString(summary.Error))
+	}
+}
+
+func logSourceName(source pb.LogSource) string {
+	switch source {
+	case pb.LogSource_LOG_SOURCE_STDOUT:
+		return "stdout"
+	case pb.LogSource_LOG_SOURCE_STDERR:
+		return "stderr"
+	}
+	return "agent"
+}
+
+func logStatusName(status pb.LogStatus) string {
+	return strings.TrimPrefix(status.String(), "LOG_STATUS_")
+}
+
+func logPhaseName(phase pb.DeployPhase) string {
+	switch phase {
+	case pb.DeployPhase_DEPLOY_PHASE_CLONE:
+		return "clone"
+	case pb.DeployPhase_DEPLOY_PHASE_INSTALL:
+		return "install"
+	case pb.DeployPhase_DEPLOY_PHASE_RUN:
+		return "run"
+	}
+	return "agent"
+}
+
+func httpStatusFromGRPC(err error) int {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.PermissionDenied:
+		return http.StatusForbidden
 	}
+	return http.StatusInternalServerError
 }
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"orchestrator/internal/auth"
+	"orchestrator/pb"
+)
+
+// AccessRules é o papel exigido por cada método e como encontrar o bot
+// afetado pela requisição.
+func (h *Handler) AccessRules() map[string]auth.Rule {
+	deployBot := func(req any) (string, bool) {
+		return req.(*pb.DeployRequest).GetBotId(), true
+	}
+	jobBot := func(req any) (string, bool) {
+		return h.service.jobBotID(req.(interface{ GetJobId() string }).GetJobId())
+	}
+	scheduleBot := func(req any) (string, bool) {
+		sched, err := h.scheduler.Get(req.(interface{ GetScheduleId() string }).GetScheduleId())
+		return sched.Bot.BotID, err == nil
+	}
+
+	return map[string]auth.Rule{
+		pb.OrchestratorService_GetJob_FullMethodName:        {Role: auth.RoleViewer, Bot: jobBot},
+		pb.OrchestratorService_ListJobs_FullMethodName:      {Role: auth.RoleViewer},
+		pb.OrchestratorService_AttachLogs_FullMethodName:    {Role: auth.RoleViewer, Bot: jobBot},
+		pb.OrchestratorService_GetJobLogs_FullMethodName:    {Role: auth.RoleViewer, Bot: jobBot},
+		pb.OrchestratorService_ListSchedules_FullMethodName: {Role: auth.RoleViewer},
+
+		pb.OrchestratorService_ExecuteDeploy_FullMethodName: {Role: auth.RoleOperator, Bot: deployBot},
+		pb.OrchestratorService_CancelJob_FullMethodName:     {Role: auth.RoleOperator, Bot: jobBot},
+
+		pb.OrchestratorService_CreateSchedule_FullMethodName: {Role: auth.RoleAdmin, Bot: func(req any) (string, bool) {
+			return req.(*pb.CreateScheduleRequest).GetBot().GetBotId(), true
+		}},
+		pb.OrchestratorService_DeleteSchedule_FullMethodName: {Role: auth.RoleAdmin, Bot: scheduleBot},
+		pb.OrchestratorService_PauseSchedule_FullMethodName:  {Role: auth.RoleAdmin, Bot: scheduleBot},
+		pb.OrchestratorService_DeleteBotVersion_FullMethodName: {Role: auth.RoleAdmin, Bot: func(req any) (string, bool) {
+			return req.(*pb.DeleteBotVersionRequest).GetBotId(), true
+		}},
+		pb.OrchestratorService_PruneBots_FullMethodName: {Role: auth.RoleAdmin, AllBots: true},
+	}
+}
+
+// jobBotID devolve o bot de um job em memória ou, para jobs que já saíram do
+// registro, do log persistido.
+func (s *OrchestratorService) jobBotID(id string) (string, bool) {
+	_, botID, err := s.findJobLogFile(id)
+	return botID, err == nil
+}
@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// outputCharset é a codificação da saída de um processo, convertida para
+// UTF-8 antes de virar linha de log.
+type outputCharset struct {
+	name string
+	// table é nil para utf-8 e auto.
+	table *charmap.Charmap
+}
+
+var (
+	charsetAuto = outputCharset{name: "auto"}
+
+	outputCharsets = []outputCharset{
+		charsetAuto,
+		{name: "utf-8"},
+		{name: "cp1252", table: charmap.Windows1252},
+		{name: "iso-8859-1", table: charmap.ISO8859_1},
+		{name: "cp850", table: charmap.CodePage850},
+	}
+
+	charsetAliases = map[string]string{
+		"":             "auto",
+		"utf8":         "utf-8",
+		"windows-1252": "cp1252",
+		"latin1":       "iso-8859-1",
+		"latin-1":      "iso-8859-1",
+		"ibm850":       "cp850",
+	}
+)
+
+// parseOutputCharset aceita os nomes de outputCharsets e alguns apelidos
+// comuns, sem diferenciar maiúsculas.
+func parseOutputCharset(name string) (outputCharset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := charsetAliases[name]; ok {
+		name = alias
+	}
+	var names []string
+	for _, c := range outputCharsets {
+		if c.name == name {
+			return c, nil
+		}
+		names = append(names, c.name)
+	}
+	return charsetAuto, fmt.Errorf("codificação desconhecida %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// decode converte raw para UTF-8. exact é false quando o texto não
+// reproduz os bytes originais (outra codificação ou bytes inválidos), caso em
+// que o log persistido guarda raw junto com a linha.
+//
+// Em auto, uma linha que não é UTF-8 válido é lida como cp1252, a codificação
+// mais comum de bots rodando no Windows; em cp1252 e iso-8859-1 todo byte é
+// um caractere, então nada vira "?".
+func (c outputCharset) decode(raw []byte) (text string, exact bool) {
+	if c.table == nil {
+		if utf8.Valid(raw) {
+			return string(raw), true
+		}
+		if c.name == "utf-8" {
+			return sanitizeUTF8(string(raw)), false
+		}
+		return decodeWith(charmap.Windows1252, raw), false
+	}
+	if isASCII(raw) {
+		return string(raw), true
+	}
+	return decodeWith(c.table, raw), false
+}
+
+// multibyte informa se um caractere pode ocupar mais de um byte, o que
+// importa ao cortar linhas longas.
+func (c outputCharset) multibyte() bool {
+	return c.table == nil
+}
+
+func decodeWith(table *charmap.Charmap, raw []byte) string {
+	var builder strings.Builder
+	builder.Grow(len(raw))
+	for _, b := range raw {
+		builder.WriteRune(table.DecodeByte(b))
+	}
+	return builder.String()
+}
+
+func isASCII(raw []byte) bool {
+	for _, b := range raw {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/pb"
+)
+
+func versionKey(job *Job) string {
+	return job.Bot.BotID + "/" + job.Bot.Version
+}
+
+// keyedLock é o lock (um canal de capacidade 1) de uma chave; refs conta
+// quem o segura ou está esperando por ele.
+type keyedLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// versionLock devolve o lock que serializa os jobs de um mesmo bot/versão, já
+// que todos usam o mesmo diretório, e a função que devolve a referência
+// quando o chamador termina de usá-lo (depois de soltá-lo, se o obteve). A
+// entrada sai do mapa quando ninguém mais a usa.
+func (s *OrchestratorService) versionLock(key string) (chan struct{}, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lock, ok := s.versionLocks[key]
+	if !ok {
+		lock = &keyedLock{ch: make(chan struct{}, 1)}
+		s.versionLocks[key] = lock
+	}
+	lock.refs++
+	return lock.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(s.versionLocks, key)
+		}
+	}
+}
+
+// acquireRun bloqueia até o job obter o lock da sua versão e uma vaga entre
+// as maxConcurrentRuns execuções simultâneas, avisando no stream enquanto
+// estiver na fila. A função devolvida libera ambos.
+func (s *OrchestratorService) acquireRun(job *Job, logStream *logSink) (func(), error) {
+	lock, unref := s.versionLock(versionKey(job))
+	queued := false
+	enqueue := func(reason string) {
+		if queued {
+			return
+		}
+		queued = true
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED, pb.LogStatus_LOG_STATUS_QUEUED, reason))
+	}
+
+	select {
+	case lock <- struct{}{}:
+	default:
+		enqueue(fmt.Sprintf("Job na fila: outra execução de %s/%s está em andamento.", job.Bot.BotID, job.Bot.Version))
+		select {
+		case lock <- struct{}{}:
+		case <-job.ctx.Done():
+			unref()
+			return nil, job.ctx.Err()
+		}
+	}
+
+	select {
+	case s.runSlots <- struct{}{}:
+	default:
+		enqueue(fmt.Sprintf("Job na fila: limite de %d execuções simultâneas atingido.", cap(s.runSlots)))
+		select {
+		case s.runSlots <- struct{}{}:
+		case <-job.ctx.Done():
+			<-lock
+			unref()
+			return nil, job.ctx.Err()
+		}
+	}
+
+	if queued {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED, pb.LogStatus_LOG_STATUS_INFO, "Job saiu da fila, iniciando execução."))
+	}
+	return func() {
+		<-s.runSlots
+		<-lock
+		unref()
+	}, nil
+}
+
+// tryLock tenta obter o lock key sem esperar. Usado pela limpeza de disco,
+// que pula o que estiver em uso.
+func (s *OrchestratorService) tryLock(key string) (func(), bool) {
+	lock, unref := s.versionLock(key)
+	select {
+	case lock <- struct{}{}:
+		return func() {
+			<-lock
+			unref()
+		}, true
+	default:
+		unref()
+		return nil, false
+	}
+}
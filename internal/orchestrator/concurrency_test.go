@@ -0,0 +1,196 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"orchestrator/pb"
+	"orchestrator/structs"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestService(t *testing.T, maxRuns int) *OrchestratorService {
+	return &OrchestratorService{
+		botsDir:        t.TempDir(),
+		logBufferLines: 100,
+		jobs:           make(map[string]*Job),
+		versionLocks:   make(map[string]*keyedLock),
+		runSlots:       make(chan struct{}, maxRuns),
+	}
+}
+
+func newTestJob(s *OrchestratorService, botID, version string) (*Job, *logSink) {
+	job := s.NewJob(&structs.Bot{BotID: botID, Version: version}, JobOrigin{})
+	return job, &logSink{job: job}
+}
+
+// logLines devolve as linhas que já estão no anel do job.
+func logLines(job *Job) []string {
+	msgs, _, _, _ := job.logs.read(0)
+	var lines []string
+	for _, msg := range msgs {
+		lines = append(lines, msg.Line)
+	}
+	return lines
+}
+
+type acquireResult struct {
+	release func()
+	err     error
+}
+
+func acquireAsync(s *OrchestratorService, job *Job, sink *logSink) <-chan acquireResult {
+	done := make(chan acquireResult, 1)
+	go func() {
+		release, err := s.acquireRun(job, sink)
+		done <- acquireResult{release, err}
+	}()
+	return done
+}
+
+// waitQueued espera o job avisar que entrou na fila.
+func waitQueued(t *testing.T, job *Job, reason string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		msgs, _, _, _ := job.logs.read(0)
+		if len(msgs) > 0 {
+			if msgs[0].Status != pb.LogStatus_LOG_STATUS_QUEUED || !strings.Contains(msgs[0].Line, reason) {
+				t.Fatalf("aviso de fila = %s %q, esperado QUEUED com %q", msgs[0].Status, msgs[0].Line, reason)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("o job não entrou na fila")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func assertNoLocks(t *testing.T, s *OrchestratorService) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.versionLocks) != 0 {
+		t.Fatalf("versionLocks tem %d entradas, esperado nenhuma", len(s.versionLocks))
+	}
+}
+
+func TestAcquireRunSameVersion(t *testing.T) {
+	s := newTestService(t, 2)
+	first, firstSink := newTestJob(s, "b1", "main")
+	second, secondSink := newTestJob(s, "b1", "main")
+
+	release, err := s.acquireRun(first, firstSink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := logLines(first); len(lines) != 0 {
+		t.Fatalf("o primeiro job não deveria ter esperado: %q", lines)
+	}
+
+	done := acquireAsync(s, second, secondSink)
+	waitQueued(t, second, "outra execução de b1/main")
+	select {
+	case <-done:
+		t.Fatal("o segundo job executou junto com o primeiro")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+	result := <-done
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	lines := logLines(second)
+	if last := lines[len(lines)-1]; !strings.Contains(last, "saiu da fila") {
+		t.Fatalf("última linha = %q, esperado o aviso de saída da fila", last)
+	}
+	result.release()
+	assertNoLocks(t, s)
+}
+
+func TestAcquireRunSlots(t *testing.T) {
+	s := newTestService(t, 1)
+	first, firstSink := newTestJob(s, "b1", "main")
+	second, secondSink := newTestJob(s, "b2", "main")
+
+	release, err := s.acquireRun(first, firstSink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := acquireAsync(s, second, secondSink)
+	waitQueued(t, second, "limite de 1 execuções simultâneas")
+
+	release()
+	result := <-done
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	result.release()
+	assertNoLocks(t, s)
+}
+
+func TestAcquireRunCancelled(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		version string // versão do segundo job
+		reason  string
+	}{
+		{"esperando a versão", "main", "outra execução"},
+		{"esperando uma vaga", "v2", "execuções simultâneas"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, 1)
+			first, firstSink := newTestJob(s, "b1", "main")
+			second, secondSink := newTestJob(s, "b1", tt.version)
+
+			release, err := s.acquireRun(first, firstSink)
+			if err != nil {
+				t.Fatal(err)
+			}
+			done := acquireAsync(s, second, secondSink)
+			waitQueued(t, second, tt.reason)
+
+			second.cancel()
+			if result := <-done; !errors.Is(result.err, context.Canceled) {
+				t.Fatalf("erro = %v, esperado context.Canceled", result.err)
+			}
+			release()
+			assertNoLocks(t, s)
+
+			// O lock da versão do job cancelado não ficou preso.
+			third, thirdSink := newTestJob(s, "b1", tt.version)
+			release, err = s.acquireRun(third, thirdSink)
+			if err != nil {
+				t.Fatal(err)
+			}
+			release()
+			if lines := logLines(third); len(lines) != 0 {
+				t.Fatalf("o terceiro job não deveria ter esperado: %q", lines)
+			}
+		})
+	}
+}
+
+func TestTryLock(t *testing.T) {
+	s := newTestService(t, 1)
+	job, sink := newTestJob(s, "b1", "main")
+	release, err := s.acquireRun(job, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.tryLock(versionKey(job)); ok {
+		t.Fatal("tryLock obteve um lock em uso")
+	}
+	release()
+	assertNoLocks(t, s)
+
+	unlock, ok := s.tryLock(versionKey(job))
+	if !ok {
+		t.Fatal("tryLock falhou com o lock livre")
+	}
+	unlock()
+	assertNoLocks(t, s)
+}
@@ -0,0 +1,49 @@
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/internal/validation"
+	"sort"
+)
+
+// botEnv monta as variáveis que o job acrescenta ao ambiente do bot: as do
+// DeployRequest e, por último, os segredos referenciados. Os valores dos
+// segredos passam a ser mascarados nos logs do job.
+func (s *OrchestratorService) botEnv(job *Job) ([]string, error) {
+	names := make([]string, 0, len(job.Bot.Env))
+	for name := range job.Bot.Env {
+		if !validation.IsEnvName(name) {
+			return nil, fmt.Errorf("nome de variável de ambiente inválido %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var env []string
+	for _, name := range names {
+		env = append(env, name+"="+job.Bot.Env[name])
+	}
+	if len(job.Bot.SecretRefs) == 0 {
+		return env, nil
+	}
+
+	envNames := make([]string, len(job.Bot.SecretRefs))
+	secretNames := make([]string, len(job.Bot.SecretRefs))
+	for i, ref := range job.Bot.SecretRefs {
+		envName, secretName, err := validation.ParseSecretRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		envNames[i], secretNames[i] = envName, secretName
+	}
+	values, err := s.secrets.Get(job.Bot.BotID, secretNames...)
+	if err != nil {
+		return nil, err
+	}
+	for i, envName := range envNames {
+		value := values[secretNames[i]]
+		job.redactor.add(value)
+		env = append(env, envName+"="+value)
+	}
+	return env, nil
+}
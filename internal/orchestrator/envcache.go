@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// envCacheFile marca um ambiente completo; só é gravado depois que a
+// instalação termina com sucesso.
+const envCacheFile = ".gobot-env.json"
+
+// venvCacheDir guarda os ambientes virtuais, um por chave de cache, e é
+// compartilhado entre bots e versões.
+func (s *OrchestratorService) venvCacheDir() string {
+	return filepath.Join(s.cacheDir, "venvs")
+}
+
+// envCacheEntry descreve um ambiente virtual do cache.
+type envCacheEntry struct {
+	Key          string    `json:"key"`
+	Python       string    `json:"python"`
+	Dependencies string    `json:"dependencies"`
+	BotID        string    `json:"bot_id"`
+	Version      string    `json:"version"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// envCacheKey calcula a chave do ambiente: o hash do arquivo de dependências
+// (e do Pipfile.lock, se houver) junto com a versão do interpretador. Quando
+// a instalação depende do próprio código do bot (pyproject, -e, -r ou
+// caminhos locais), o diretório e o commit entram na chave, e o ambiente só é
+// reaproveitado pela mesma versão no mesmo commit.
+func envCacheKey(depFile, pythonVersion, sourceDir, commit string) (string, error) {
+	data, err := os.ReadFile(depFile)
+	if err != nil {
+		return "", fmt.Errorf("erro ao ler %s: %v", filepath.Base(depFile), err)
+	}
+	kind := dependencyKind(depFile)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "python %s\nkind %s\n", pythonVersion, kind)
+	h.Write(data)
+	if kind == "pipfile" {
+		if lock, err := os.ReadFile(filepath.Join(filepath.Dir(depFile), "Pipfile.lock")); err == nil {
+			h.Write([]byte("\nPipfile.lock\n"))
+			h.Write(lock)
+		}
+	}
+	if kind == "pyproject" || (kind == "requirements" && requirementsReferenceSource(data)) {
+		fmt.Fprintf(h, "\nsource %s %s\n", sourceDir, commit)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32], nil
+}
+
+// requirementsReferenceSource informa se um requirements.txt instala algo a
+// partir do repositório do bot, caso em que o conteúdo do arquivo não basta
+// para identificar o ambiente.
+func requirementsReferenceSource(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		for _, prefix := range []string{"-e", "--editable", "-r", "--requirement", "-c", "--constraint", ".", "/", "file:"} {
+			if strings.HasPrefix(line, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// venvCachePath devolve o diretório do ambiente com a chave key.
+func (s *OrchestratorService) venvCachePath(key string) string {
+	path, _ := filepath.Abs(filepath.Join(s.venvCacheDir(), key))
+	return path
+}
+
+// readEnvCache devolve a entrada de um ambiente completo, ou false se ele não
+// existe ou ficou pela metade.
+func readEnvCache(venvPath, key string) (envCacheEntry, bool) {
+	data, err := os.ReadFile(filepath.Join(venvPath, envCacheFile))
+	if err != nil {
+		return envCacheEntry{}, false
+	}
+	var entry envCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key {
+		return envCacheEntry{}, false
+	}
+	if _, err := os.Stat(venvExecutable(venvPath, "python")); err != nil {
+		return envCacheEntry{}, false
+	}
+	return entry, true
+}
+
+func writeEnvCache(venvPath string, entry envCacheEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(venvPath, envCacheFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erro ao registrar o ambiente no cache: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// touchEnvCache atualiza a data de último uso do ambiente, usada para
+// descartar ambientes esquecidos.
+func touchEnvCache(venvPath string) {
+	now := time.Now()
+	os.Chtimes(filepath.Join(venvPath, envCacheFile), now, now)
+}
+
+// pipCacheEnv aponta o cache de wheels do pip (e do pipenv) para um
+// diretório compartilhado por todas as instalações.
+func (s *OrchestratorService) pipCacheEnv() []string {
+	dir, _ := filepath.Abs(filepath.Join(s.cacheDir, "pip"))
+	return []string{"PIP_CACHE_DIR=" + dir, "PIPENV_CACHE_DIR=" + dir}
+}
@@ -0,0 +1,155 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"orchestrator/pb"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// gitCommand prepara um comando git que nunca pede credenciais no terminal
+// e que é encerrado junto com o job. auth só deve ser passado para comandos
+// que acessam o repositório remoto.
+func gitCommand(ctx context.Context, dir string, auth *gitAuth, args ...string) *exec.Cmd {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if auth != nil {
+		args = append(append([]string{}, auth.config...), args...)
+		env = append(env, auth.env...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	bindProcessTree(cmd)
+	return cmd
+}
+
+// gitOutput executa um comando git e devolve o stdout sem espaços nas pontas.
+func gitOutput(ctx context.Context, dir string, auth *gitAuth, args ...string) (string, error) {
+	cmd := gitCommand(ctx, dir, auth, args...)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v - %s", args[0], err, strings.TrimSpace(sanitizeUTF8(stderr.String())))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// runGitStreaming executa um comando git repassando stdout e stderr para o
+// stream de logs. Em caso de falha, o stderr acumulado vai na mensagem de erro.
+func runGitStreaming(ctx context.Context, dir string, auth *gitAuth, logStream *logSink, args ...string) error {
+	cmd := gitCommand(ctx, dir, auth, args...)
+
+	// O stderr vai na mensagem de erro, sem os estados de progresso.
+	var stderrLines []string
+	waitOutput := logStream.attachOutput(cmd, pb.DeployPhase_DEPLOY_PHASE_CLONE, charsetAuto, func(source pb.LogSource, line outputLine) {
+		if source == pb.LogSource_LOG_SOURCE_STDERR && !line.progress {
+			stderrLines = append(stderrLines, line.text)
+		}
+	})
+	if err := cmd.Start(); err != nil {
+		waitOutput()
+		return fmt.Errorf("falha ao iniciar git %s: %v", args[0], err)
+	}
+	cmdErr := cmd.Wait()
+	waitOutput()
+
+	if cmdErr != nil {
+		errMsg := strings.Join(stderrLines, "; ")
+		return fmt.Errorf("erro durante o git %s: %v - stderr: %s", args[0], cmdErr, errMsg)
+	}
+	return nil
+}
+
+// resolveRemoteVersion consulta o repositório com git ls-remote e devolve a
+// ref completa (branch ou tag) que corresponde a version e o SHA do commit
+// para o qual ela aponta hoje.
+func resolveRemoteVersion(ctx context.Context, remote gitRemote, version string) (string, string, error) {
+	// O padrão "^{}" é necessário para o ls-remote listar o commit de tags
+	// anotadas.
+	out, err := gitOutput(ctx, "", remote.auth, "ls-remote", remote.url, version, version+"^{}")
+	if err != nil {
+		return "", "", err
+	}
+
+	ref, sha, ok := matchRemoteRef(out, version)
+	if !ok {
+		return "", "", fmt.Errorf("versão %s não encontrada em %s (commits precisam ser informados com o SHA completo)", version, remote.url)
+	}
+	return ref, sha, nil
+}
+
+// matchRemoteRef procura version na saída do git ls-remote. Branches têm
+// precedência sobre tags e, numa tag anotada, vale o commit apontado por ela
+// ("^{}"), não o objeto da tag.
+func matchRemoteRef(lsRemote, version string) (ref, sha string, ok bool) {
+	refs := make(map[string]string)
+	for _, line := range strings.Split(lsRemote, "\n") {
+		sha, ref, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if ok {
+			refs[ref] = sha
+		}
+	}
+
+	if sha, ok := refs["refs/heads/"+version]; ok {
+		return "refs/heads/" + version, sha, true
+	}
+	tag := "refs/tags/" + version
+	if sha, ok := refs[tag+"^{}"]; ok {
+		return tag, sha, true
+	}
+	if sha, ok := refs[tag]; ok {
+		return tag, sha, true
+	}
+	return "", "", false
+}
+
+// syncSource deixa sourceDir no commit de rev com um fetch raso (--depth 1)
+// apenas da ref pedida. Um clone que já está no commit não é tocado.
+func syncSource(ctx context.Context, sourceDir string, remote gitRemote, rev revision, logStream *logSink) error {
+	fresh := false
+	if _, err := os.Stat(sourceDir); err == nil {
+		localSHA, err := gitOutput(ctx, sourceDir, nil, "rev-parse", "HEAD")
+		if err != nil {
+			return fmt.Errorf("clone local inválido (use force_reclone): %v", err)
+		}
+		if localSHA == rev.Commit {
+			logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+				"Versão já existe localmente e está atualizada. Pulando clone."))
+			return nil
+		}
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+			fmt.Sprintf("Versão %s mudou: atualizando de %s para %s", rev.Version, localSHA, rev.Commit)))
+	} else {
+		if _, err := gitOutput(ctx, "", nil, "init", "-q", sourceDir); err != nil {
+			return fmt.Errorf("erro ao preparar o diretório do clone: %v", err)
+		}
+		fresh = true
+	}
+
+	err := fetchAndCheckout(ctx, sourceDir, remote, rev.fetchRef(), logStream)
+	if err != nil {
+		if fresh {
+			// Um repositório vazio seria tomado por um clone inválido na
+			// próxima execução.
+			os.RemoveAll(sourceDir)
+		}
+		return err
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_SUCCESS, "Clone finalizado com sucesso!"))
+	return nil
+}
+
+func fetchAndCheckout(ctx context.Context, sourceDir string, remote gitRemote, ref string, logStream *logSink) error {
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+		fmt.Sprintf("Executando: git fetch --depth 1 %s %s", remote.url, ref)))
+	if err := runGitStreaming(ctx, sourceDir, remote.auth, logStream, "fetch", "--depth", "1", "--no-tags", remote.url, ref); err != nil {
+		return err
+	}
+	if _, err := gitOutput(ctx, sourceDir, nil, "checkout", "-q", "--force", "--detach", "FETCH_HEAD"); err != nil {
+		return fmt.Errorf("erro ao fazer checkout de %s: %v", ref, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/internal/credentials"
+	"orchestrator/pb"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// askpassScript responde ao prompt do git com as variáveis de ambiente do
+// próprio processo git, para que o token não fique gravado em disco. O Git
+// for Windows também executa o GIT_ASKPASS pelo sh embutido.
+const askpassScript = `#!/bin/sh
+case "$1" in
+Username*) printf '%s\n' "$GOBOT_GIT_USERNAME" ;;
+*) printf '%s\n' "$GOBOT_GIT_PASSWORD" ;;
+esac
+`
+
+// gitAuth é o que precisa ser injetado nos comandos git que falam com o
+// repositório remoto. Comandos locais e o processo do bot não recebem nada.
+type gitAuth struct {
+	env    []string
+	config []string
+	tmpDir string
+}
+
+// gitRemote é o repositório de um job junto com a credencial para acessá-lo.
+type gitRemote struct {
+	url  string
+	auth *gitAuth
+}
+
+func (a *gitAuth) close() {
+	if a != nil && a.tmpDir != "" {
+		os.RemoveAll(a.tmpDir)
+	}
+}
+
+// newGitAuth prepara GIT_ASKPASS (token HTTPS) ou GIT_SSH_COMMAND (deploy
+// key) em um diretório temporário que deve ser removido com close.
+func newGitAuth(cred credentials.Credential) (*gitAuth, error) {
+	tmpDir, err := os.MkdirTemp("", "gobot-git-")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao preparar a credencial: %v", err)
+	}
+	auth := &gitAuth{tmpDir: tmpDir}
+
+	switch cred.Type {
+	case credentials.HTTPSToken:
+		askpass := filepath.Join(tmpDir, "askpass.sh")
+		if err := os.WriteFile(askpass, []byte(askpassScript), 0700); err != nil {
+			auth.close()
+			return nil, fmt.Errorf("erro ao preparar a credencial: %v", err)
+		}
+		username := cred.Username
+		if username == "" {
+			username = "x-access-token"
+		}
+		auth.env = []string{
+			"GIT_ASKPASS=" + askpass,
+			"GOBOT_GIT_USERNAME=" + username,
+			"GOBOT_GIT_PASSWORD=" + cred.Token,
+		}
+		// Sem isso um credential helper do host poderia responder antes.
+		auth.config = []string{"-c", "credential.helper="}
+
+	case credentials.SSHKey:
+		keyFile := cred.PrivateKeyFile
+		if cred.PrivateKey != "" {
+			keyFile = filepath.Join(tmpDir, "id_key")
+			key := cred.PrivateKey
+			if !strings.HasSuffix(key, "\n") {
+				key += "\n"
+			}
+			if err := os.WriteFile(keyFile, []byte(key), 0600); err != nil {
+				auth.close()
+				return nil, fmt.Errorf("erro ao preparar a credencial: %v", err)
+			}
+		}
+		hostCheck := "-o StrictHostKeyChecking=accept-new"
+		if cred.KnownHostsFile != "" {
+			hostCheck = "-o StrictHostKeyChecking=yes -o UserKnownHostsFile=" + shellQuote(filepath.ToSlash(cred.KnownHostsFile))
+		}
+		auth.env = []string{
+			fmt.Sprintf("GIT_SSH_COMMAND=ssh -i %s -o IdentitiesOnly=yes -o BatchMode=yes %s",
+				shellQuote(filepath.ToSlash(keyFile)), hostCheck),
+		}
+	}
+	return auth, nil
+}
+
+// shellQuote protege um caminho para o GIT_SSH_COMMAND, que é interpretado
+// por um shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// gitRemoteFor carrega a credencial pedida no job, se houver, e registra os
+// seus segredos para serem mascarados nos logs.
+func (s *OrchestratorService) gitRemoteFor(job *Job, logStream *logSink) (gitRemote, error) {
+	remote := gitRemote{url: job.Bot.GitRepo}
+	if job.Bot.Credential == "" {
+		return remote, nil
+	}
+
+	cred, err := s.credentials.Get(job.Bot.Credential, job.Bot.BotID)
+	if err != nil {
+		return remote, err
+	}
+	job.redactor.add(cred.Secrets()...)
+
+	auth, err := newGitAuth(cred)
+	if err != nil {
+		return remote, err
+	}
+	remote.auth = auth
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+		fmt.Sprintf("Usando a credencial %q (%s).", cred.Name, cred.Type)))
+	return remote, nil
+}
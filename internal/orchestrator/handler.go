@@ -1,61 +1,146 @@
 package orchestrator
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"orchestrator/internal/auth"
+	"orchestrator/internal/config"
+	"orchestrator/internal/scheduler"
+	"orchestrator/internal/validation"
 	"orchestrator/pb"
 	"orchestrator/structs"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Handler struct {
 	pb.UnimplementedOrchestratorServiceServer
-	service *OrchestratorService
+	service   *OrchestratorService
+	scheduler *scheduler.Scheduler
+	policy    validation.Policy
 }
 
-func NewOrchestratorServiceServer() *Handler {
+func NewOrchestratorServiceServer(cfg config.Server) (*Handler, error) {
+	service := NewOrchestratorService(cfg)
+	policy := validation.NewPolicy(cfg.Git.AllowedSchemes, cfg.Git.AllowedHosts)
+	sched, err := scheduler.New(cfg.SchedulesFile, func(sched scheduler.Schedule) (string, error) {
+		// O arquivo de agendamentos pode ter sido editado à mão ou criado
+		// antes de uma mudança na política.
+		if err := policy.DeployRequest(botToRequest(&sched.Bot), "bot."); err != nil {
+			return "", err
+		}
+		job := service.StartJob(&sched.Bot, JobOrigin{ScheduleID: sched.ID, RequestedBy: sched.CreatedBy})
+		return job.ID, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	go sched.Run(context.Background())
+	go service.runJanitor(context.Background(), cfg.Retention.JanitorInterval)
+
 	return &Handler{
-		service: NewOrchestratorService(),
+		service:   service,
+		scheduler: sched,
+		policy:    policy,
+	}, nil
+}
+
+func botFromRequest(req *pb.DeployRequest) *structs.Bot {
+	return &structs.Bot{
+		BotID:          req.GetBotId(),
+		GitRepo:        req.GetGitRepo(),
+		Version:        req.GetVersion(),
+		ForceReclone:   req.GetForceReclone(),
+		Credential:     req.GetCredential(),
+		Env:            req.GetEnv(),
+		SecretRefs:     req.GetSecretRefs(),
+		TimeoutSeconds: req.GetTimeoutSeconds(),
 	}
 }
 
-func (h *Handler) ExecuteDeploy(req *pb.DeployRequest, stream pb.OrchestratorService_ExecuteDeployServer) error {
-	fmt.Printf("Received DeployRequest: %+v\n", req)
-	logStream := make(chan *pb.LogResponse)
-	done := make(chan error, 1)
+func botToRequest(bot *structs.Bot) *pb.DeployRequest {
+	return &pb.DeployRequest{
+		BotId:          bot.BotID,
+		GitRepo:        bot.GitRepo,
+		Version:        bot.Version,
+		ForceReclone:   bot.ForceReclone,
+		Credential:     bot.Credential,
+		Env:            bot.Env,
+		SecretRefs:     bot.SecretRefs,
+		TimeoutSeconds: bot.TimeoutSeconds,
+	}
+}
 
-	deployRequest := &structs.Bot{
-		BotID:   req.BotId,
-		GitRepo: req.GitRepo,
-		Version: req.Version,
+func (h *Handler) ExecuteDeploy(req *pb.DeployRequest, stream pb.OrchestratorService_ExecuteDeployServer) error {
+	requestedBy := auth.Subject(stream.Context())
+	// Só os nomes das variáveis: os valores do env podem ser tokens.
+	fmt.Printf("Received DeployRequest from %q: bot_id=%q version=%q git_repo=%q env=%v\n",
+		requestedBy, req.GetBotId(), req.GetVersion(), redactURL(req.GetGitRepo()), sortedKeys(req.GetEnv()))
+	if err := h.policy.DeployRequest(req, ""); err != nil {
+		return err
 	}
 
-	go func() {
-		err := h.service.ExecuteDeployment(deployRequest, logStream)
-		if err != nil {
-			done <- err
-		}
-		err = h.service.RunBot(deployRequest, logStream)
-		if err != nil {
-			done <- err
-		}
-		close(logStream)
-		done <- nil
-	}()
-
-	for {
-		select {
-		case logMsg, ok := <-logStream:
-			if !ok {
-				return <-done
-			}
-			if err := stream.Send(logMsg); err != nil {
-				return err
+	job := h.service.StartJob(botFromRequest(req), JobOrigin{RequestedBy: requestedBy})
+
+	// O job continua rodando se o cliente desconectar, a menos que ele tenha
+	// pedido o contrário; ele pode voltar a acompanhar a saída com
+	// AttachLogs.
+	if err := h.service.AttachLogs(stream.Context(), job.ID, 0, stream.Send); err != nil {
+		if req.CancelOnDisconnect && stream.Context().Err() != nil {
+			if _, err := h.service.CancelJob(job.ID); err == nil {
+				fmt.Printf("[%s] cliente desconectou; job cancelado (cancel_on_disconnect)\n", job.ID)
 			}
+		}
+		return err
+	}
+	// Uma falha do job vira um status gRPC próprio para cada motivo, com o
+	// resumo nos detalhes; o resumo também já foi enviado no stream.
+	return h.service.JobStatus(job.ID)
+}
 
-		case err := <-done:
-			return err
+func (h *Handler) AttachLogs(req *pb.AttachLogsRequest, stream pb.OrchestratorService_AttachLogsServer) error {
+	err := h.service.AttachLogs(stream.Context(), req.JobId, req.FromOffset, stream.Send)
+	if errors.Is(err, ErrJobNotFound) {
+		return status.Errorf(codes.NotFound, "job %s não encontrado", req.JobId)
+	}
+	if err != nil {
+		return err
+	}
+	return h.service.JobStatus(req.JobId)
+}
 
-		case <-stream.Context().Done():
-			return stream.Context().Err()
-		}
+func (h *Handler) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	job, ok := h.service.GetJob(req.JobId)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "job %s não encontrado", req.JobId)
+	}
+	return job, nil
+}
+
+func (h *Handler) ListJobs(ctx context.Context, req *pb.ListJobsRequest) (*pb.ListJobsResponse, error) {
+	visible := func(botID string) bool { return auth.CanAccessBot(ctx, botID) }
+	return &pb.ListJobsResponse{Jobs: h.service.ListJobs(req.BotId, int(req.Limit), visible)}, nil
+}
+
+func (h *Handler) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.Job, error) {
+	job, err := h.service.CancelJob(req.JobId)
+	switch {
+	case errors.Is(err, ErrJobNotFound):
+		return nil, status.Errorf(codes.NotFound, "job %s não encontrado", req.JobId)
+	case errors.Is(err, ErrJobFinished):
+		return nil, status.Errorf(codes.FailedPrecondition, "job %s já finalizado", req.JobId)
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return job, nil
+}
+
+func (h *Handler) GetJobLogs(req *pb.GetJobLogsRequest, stream pb.OrchestratorService_GetJobLogsServer) error {
+	err := h.service.GetJobLogs(req.JobId, stream.Send)
+	if errors.Is(err, ErrJobNotFound) {
+		return status.Errorf(codes.NotFound, "log do job %s não encontrado", req.JobId)
 	}
+	return err
 }
@@ -0,0 +1,352 @@
+package orchestrator
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"orchestrator/pb"
+	"orchestrator/structs"
+	"os/exec"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var (
+	ErrJobNotFound = errors.New("job não encontrado")
+	ErrJobFinished = errors.New("job já finalizado")
+)
+
+// JobOrigin identifica o que disparou um job.
+type JobOrigin struct {
+	ScheduleID string
+	// RequestedBy é a identidade autenticada de quem pediu a execução.
+	RequestedBy string
+}
+
+// Job é o registro de uma execução de ExecuteDeploy. Os campos mutáveis são
+// protegidos pelo mu do OrchestratorService.
+type Job struct {
+	ID         string
+	Bot        structs.Bot
+	Origin     JobOrigin
+	State      pb.JobState
+	StartedAt  time.Time
+	FinishedAt time.Time
+	ExitCode   int
+	Err        string
+	// ResolvedCommit é o SHA efetivamente implantado.
+	ResolvedCommit string
+	// LimitExceeded diz qual limite levou o agente a encerrar o bot.
+	LimitExceeded string
+	// Phase é a última fase alcançada; FailureReason e Signal explicam a
+	// falha, quando houver.
+	Phase         pb.DeployPhase
+	FailureReason pb.FailureReason
+	Signal        string
+	// durations acumula o tempo passado em cada estado não final.
+	durations  map[pb.JobState]time.Duration
+	stateSince time.Time
+	// venvKey é o ambiente do cache usado pelo job, que a limpeza de disco
+	// não pode remover enquanto ele roda.
+	venvKey string
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	logs     *jobLog
+	redactor *redactor
+	err      error
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+func isFinalState(state pb.JobState) bool {
+	switch state {
+	case pb.JobState_JOB_STATE_SUCCEEDED, pb.JobState_JOB_STATE_FAILED, pb.JobState_JOB_STATE_CANCELLED:
+		return true
+	}
+	return false
+}
+
+// phaseForState devolve a fase de deploy correspondente ao estado do job.
+func phaseForState(job *Job) pb.DeployPhase {
+	switch job.State {
+	case pb.JobState_JOB_STATE_CLONING:
+		return pb.DeployPhase_DEPLOY_PHASE_CLONE
+	case pb.JobState_JOB_STATE_INSTALLING:
+		return pb.DeployPhase_DEPLOY_PHASE_INSTALL
+	case pb.JobState_JOB_STATE_RUNNING:
+		return pb.DeployPhase_DEPLOY_PHASE_RUN
+	}
+	return pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED
+}
+
+func exitCodeFromError(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+func (j *Job) toProto() *pb.Job {
+	job := &pb.Job{
+		JobId:          j.ID,
+		BotId:          j.Bot.BotID,
+		GitRepo:        redactURL(j.Bot.GitRepo),
+		Version:        j.Bot.Version,
+		State:          j.State,
+		StartedAt:      timestamppb.New(j.StartedAt),
+		ExitCode:       int32(j.ExitCode),
+		Error:          j.Err,
+		ScheduleId:     j.Origin.ScheduleID,
+		RequestedBy:    j.Origin.RequestedBy,
+		ResolvedCommit: j.ResolvedCommit,
+		LimitExceeded:  j.LimitExceeded,
+	}
+	if !j.FinishedAt.IsZero() {
+		job.FinishedAt = timestamppb.New(j.FinishedAt)
+		job.Summary = j.summary()
+	}
+	return job
+}
+
+func (s *OrchestratorService) NewJob(bot *structs.Bot, origin JobOrigin) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Now()
+	job := &Job{
+		ID:         newJobID(),
+		Bot:        *bot,
+		Origin:     origin,
+		State:      pb.JobState_JOB_STATE_QUEUED,
+		StartedAt:  now,
+		ExitCode:   -1,
+		durations:  make(map[pb.JobState]time.Duration),
+		stateSince: now,
+		ctx:        ctx,
+		cancel:     cancel,
+		redactor:   &redactor{},
+	}
+	job.logs = newJobLog(job.ID, s.logBufferLines)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	s.jobOrder = append(s.jobOrder, job.ID)
+	s.forgetFinishedJobs(now)
+	return job
+}
+
+// forgetFinishedJobs tira do registro os jobs finalizados além dos
+// jobHistory mais recentes e os que terminaram há mais de jobHistoryTTL. O
+// log deles continua no arquivo, servido por GetJobLogs e AttachLogs.
+// Chamado com s.mu travado.
+func (s *OrchestratorService) forgetFinishedJobs(now time.Time) {
+	keep := make([]bool, len(s.jobOrder))
+	finished := 0
+	for i := len(s.jobOrder) - 1; i >= 0; i-- {
+		job := s.jobs[s.jobOrder[i]]
+		if !isFinalState(job.State) {
+			keep[i] = true
+			continue
+		}
+		finished++
+		expired := s.jobHistoryTTL > 0 && now.Sub(job.FinishedAt) > s.jobHistoryTTL
+		keep[i] = finished <= s.jobHistory && !expired
+	}
+	order := s.jobOrder[:0]
+	for i, id := range s.jobOrder {
+		if keep[i] {
+			order = append(order, id)
+		} else {
+			delete(s.jobs, id)
+		}
+	}
+	s.jobOrder = order
+}
+
+func (s *OrchestratorService) setJobState(job *Job, state pb.JobState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !isFinalState(job.State) && job.State != state {
+		job.leaveState(time.Now())
+		job.State = state
+	}
+}
+
+// leaveState contabiliza o tempo passado no estado atual. Chamado com s.mu
+// travado.
+func (j *Job) leaveState(now time.Time) {
+	j.durations[j.State] += now.Sub(j.stateSince)
+	j.stateSince = now
+}
+
+func (s *OrchestratorService) setResolvedCommit(job *Job, sha string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.ResolvedCommit = sha
+}
+
+func (s *OrchestratorService) setVenvKey(job *Job, key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.venvKey = key
+}
+
+func (s *OrchestratorService) setLimitExceeded(job *Job, limit string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job.LimitExceeded = limit
+}
+
+// finishJob encerra o job. reason é o motivo da falha conforme a etapa que
+// falhou; um limite excedido tem precedência sobre ele.
+func (s *OrchestratorService) finishJob(job *Job, state pb.JobState, reason pb.FailureReason, exitCode int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if isFinalState(job.State) {
+		return
+	}
+	switch job.LimitExceeded {
+	case limitTimeout, limitInstallTimeout:
+		reason = pb.FailureReason_FAILURE_REASON_TIMEOUT
+	case limitMemory, limitOpenFiles:
+		reason = pb.FailureReason_FAILURE_REASON_RESOURCE_LIMIT
+	}
+	if state == pb.JobState_JOB_STATE_SUCCEEDED {
+		reason = pb.FailureReason_FAILURE_REASON_UNSPECIFIED
+	}
+
+	now := time.Now()
+	job.leaveState(now)
+	job.Phase = phaseForState(job)
+	job.State = state
+	job.FinishedAt = now
+	job.ExitCode = exitCode
+	job.FailureReason = reason
+	job.Signal = exitSignal(err)
+	job.err = err
+	if err != nil {
+		// O erro vai para o cliente e para o registro do job; o stderr do git
+		// que ele carrega pode conter segredos.
+		if msg := job.redactor.redact(err.Error()); msg != err.Error() {
+			job.err = errors.New(msg)
+		}
+		job.Err = job.err.Error()
+	}
+}
+
+// StartJob registra um novo job e o executa em segundo plano. A execução não
+// depende de nenhum stream: os logs ficam no buffer do job e podem ser lidos
+// com AttachLogs a qualquer momento.
+func (s *OrchestratorService) StartJob(bot *structs.Bot, origin JobOrigin) *Job {
+	job := s.NewJob(bot, origin)
+
+	go func() {
+		logStream := s.openLogSink(job)
+		s.RunJob(job, logStream)
+		logStream.send(s.summaryLog(job))
+		logStream.close()
+	}()
+
+	return job
+}
+
+// AttachLogs reproduz os logs do job a partir de offset e acompanha a saída
+// ao vivo até o job terminar ou ctx ser cancelado. Jobs finalizados, inclusive
+// os que já saíram do registro, são lidos do arquivo de log.
+func (s *OrchestratorService) AttachLogs(ctx context.Context, id string, offset int64, send func(*pb.LogResponse) error) error {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	s.mu.Unlock()
+	if ok {
+		next, err := job.logs.follow(ctx, offset, send)
+		if !errors.Is(err, errLogReleased) {
+			return err
+		}
+		offset = next
+	}
+	return s.replayJobLog(ctx, id, offset, send)
+}
+
+// JobStatus devolve o status gRPC com que um job finalizado terminou; nil se
+// ele teve sucesso ou ainda não terminou. Para jobs que já saíram do
+// registro, o status vem do resumo gravado no fim do log.
+func (s *OrchestratorService) JobStatus(id string) error {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	if ok {
+		defer s.mu.Unlock()
+		if job.err == nil || !isFinalState(job.State) {
+			return nil
+		}
+		return job.status().Err()
+	}
+	s.mu.Unlock()
+
+	summary, err := s.loggedSummary(id)
+	if err != nil || summary == nil || summary.State == pb.JobState_JOB_STATE_SUCCEEDED {
+		return nil
+	}
+	return summaryStatus(id, summary).Err()
+}
+
+// GetJob devolve uma cópia do job em formato protobuf.
+func (s *OrchestratorService) GetJob(id string) (*pb.Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return job.toProto(), true
+}
+
+// ListJobs devolve os jobs do mais recente para o mais antigo, opcionalmente
+// filtrados por bot e limitados a limit itens. visible, quando não é nil,
+// esconde os bots a que quem chama não tem acesso.
+func (s *OrchestratorService) ListJobs(botID string, limit int, visible func(botID string) bool) []*pb.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*pb.Job, 0, len(s.jobOrder))
+	for i := len(s.jobOrder) - 1; i >= 0; i-- {
+		job := s.jobs[s.jobOrder[i]]
+		if botID != "" && job.Bot.BotID != botID {
+			continue
+		}
+		if visible != nil && !visible(job.Bot.BotID) {
+			continue
+		}
+		jobs = append(jobs, job.toProto())
+		if limit > 0 && len(jobs) >= limit {
+			break
+		}
+	}
+	return jobs
+}
+
+// CancelJob interrompe o job, encerrando o processo em execução e todos os
+// seus filhos.
+func (s *OrchestratorService) CancelJob(id string) (*pb.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	if isFinalState(job.State) {
+		return nil, ErrJobFinished
+	}
+	job.cancel()
+	return job.toProto(), nil
+}
@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"errors"
+	"orchestrator/pb"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// runTestJob cria um job com log em arquivo e o encerra como RunJob
+// encerraria, com a linha de resumo no fim.
+func runTestJob(s *OrchestratorService, botID string, state pb.JobState, reason pb.FailureReason, err error) *Job {
+	job, _ := newTestJob(s, botID, "main")
+	sink := s.openLogSink(job)
+	sink.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_INFO, "executando"))
+	s.finishJob(job, state, reason, 1, err)
+	sink.send(s.summaryLog(job))
+	sink.close()
+	return job
+}
+
+func TestJobStatusForgottenJob(t *testing.T) {
+	tests := []struct {
+		name     string
+		state    pb.JobState
+		reason   pb.FailureReason
+		err      error
+		wantCode codes.Code
+	}{
+		{"sucesso", pb.JobState_JOB_STATE_SUCCEEDED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, nil, codes.OK},
+		{"falha do bot", pb.JobState_JOB_STATE_FAILED, pb.FailureReason_FAILURE_REASON_BOT_EXIT, errors.New("exit status 1"), codes.Unknown},
+		{"cancelado", pb.JobState_JOB_STATE_CANCELLED, pb.FailureReason_FAILURE_REASON_CANCELLED, errors.New("context canceled"), codes.Canceled},
+		{"clone", pb.JobState_JOB_STATE_FAILED, pb.FailureReason_FAILURE_REASON_CLONE, errors.New("erro no clone"), codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, 1)
+			job := runTestJob(s, "b1", tt.state, tt.reason, tt.err)
+			live := s.JobStatus(job.ID)
+
+			s.mu.Lock()
+			delete(s.jobs, job.ID)
+			s.mu.Unlock()
+			got := s.JobStatus(job.ID)
+
+			if code := status.Code(got); code != tt.wantCode {
+				t.Fatalf("JobStatus = %v, esperado código %s", got, tt.wantCode)
+			}
+			if tt.wantCode == codes.OK {
+				return
+			}
+			// O status lido do log é o mesmo do job ainda no registro.
+			gotStatus, liveStatus := status.Convert(got), status.Convert(live)
+			if gotStatus.Message() != liveStatus.Message() {
+				t.Fatalf("mensagem = %q, esperado %q", gotStatus.Message(), liveStatus.Message())
+			}
+			gotDetails, liveDetails := gotStatus.Details(), liveStatus.Details()
+			if len(gotDetails) != 2 || len(gotDetails) != len(liveDetails) {
+				t.Fatalf("detalhes = %v, esperado %v", gotDetails, liveDetails)
+			}
+			for i := range gotDetails {
+				if !proto.Equal(gotDetails[i].(proto.Message), liveDetails[i].(proto.Message)) {
+					t.Fatalf("detalhe %d = %v, esperado %v", i, gotDetails[i], liveDetails[i])
+				}
+			}
+		})
+	}
+}
+
+func TestJobStatusUnknownJob(t *testing.T) {
+	s := newTestService(t, 1)
+	if err := s.JobStatus("0123456789abcdef"); err != nil {
+		t.Fatalf("JobStatus de um job inexistente = %v, esperado nil", err)
+	}
+}
+
+func TestForgetFinishedJobs(t *testing.T) {
+	now := time.Now()
+	// Do mais antigo para o mais novo; zero é um job que ainda roda.
+	finishedAgo := []time.Duration{0, 10 * time.Minute, 5 * time.Minute, 2 * time.Hour, time.Minute}
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		want []int // índices dos jobs que ficam no registro
+	}{
+		{"só o limite de quantidade", 0, []int{0, 2, 3, 4}},
+		{"quantidade e idade", time.Hour, []int{0, 2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, 1)
+			s.jobHistory = 3
+			s.jobHistoryTTL = tt.ttl
+			var jobs []*Job
+			for range finishedAgo {
+				job, _ := newTestJob(s, "b1", "main")
+				jobs = append(jobs, job)
+			}
+
+			s.mu.Lock()
+			for i, ago := range finishedAgo {
+				if ago > 0 {
+					jobs[i].State = pb.JobState_JOB_STATE_SUCCEEDED
+					jobs[i].FinishedAt = now.Add(-ago)
+				}
+			}
+			s.forgetFinishedJobs(now)
+			order := append([]string(nil), s.jobOrder...)
+			registered := len(s.jobs)
+			s.mu.Unlock()
+
+			var want []string
+			for _, i := range tt.want {
+				want = append(want, jobs[i].ID)
+			}
+			if !reflect.DeepEqual(order, want) || registered != len(want) {
+				t.Fatalf("registro = %v (%d jobs), esperado %v", order, registered, want)
+			}
+		})
+	}
+}
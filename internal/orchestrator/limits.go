@@ -0,0 +1,81 @@
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Motivos gravados em Job.LimitExceeded quando o agente encerra o bot.
+const (
+	limitTimeout        = "timeout"
+	limitInstallTimeout = "install_timeout"
+	limitMemory         = "memory"
+	limitOpenFiles      = "open_files"
+)
+
+// resourceLimits são os limites aplicados ao processo do bot. Zero significa
+// sem limite.
+type resourceLimits struct {
+	MemoryBytes int64
+	CPUs        float64
+	Processes   int64
+	OpenFiles   int64
+}
+
+func (l resourceLimits) empty() bool {
+	return l == resourceLimits{}
+}
+
+func (l resourceLimits) String() string {
+	var parts []string
+	if l.MemoryBytes > 0 {
+		parts = append(parts, "memória "+config.FormatBytes(l.MemoryBytes))
+	}
+	if l.CPUs > 0 {
+		parts = append(parts, "CPU "+strconv.FormatFloat(l.CPUs, 'g', -1, 64))
+	}
+	if l.Processes > 0 {
+		parts = append(parts, fmt.Sprintf("processos %d", l.Processes))
+	}
+	if l.OpenFiles > 0 {
+		parts = append(parts, fmt.Sprintf("arquivos abertos %d", l.OpenFiles))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// restrict combina dois conjuntos de limites ficando, em cada campo, com o
+// mais restritivo. Assim o manifesto do bot pode apertar, mas nunca afrouxar,
+// os limites do agente.
+func (l resourceLimits) restrict(other resourceLimits) resourceLimits {
+	minInt := func(a, b int64) int64 {
+		if a == 0 || (b != 0 && b < a) {
+			return b
+		}
+		return a
+	}
+	cpus := l.CPUs
+	if cpus == 0 || (other.CPUs != 0 && other.CPUs < cpus) {
+		cpus = other.CPUs
+	}
+	return resourceLimits{
+		MemoryBytes: minInt(l.MemoryBytes, other.MemoryBytes),
+		CPUs:        cpus,
+		Processes:   minInt(l.Processes, other.Processes),
+		OpenFiles:   minInt(l.OpenFiles, other.OpenFiles),
+	}
+}
+
+// botTimeout escolhe o tempo limite do bot: o do DeployRequest, depois o do
+// manifesto e, por fim, o padrão do agente.
+func (s *OrchestratorService) botTimeout(job *Job, manifest *botManifest) time.Duration {
+	if job.Bot.TimeoutSeconds > 0 {
+		return time.Duration(job.Bot.TimeoutSeconds) * time.Second
+	}
+	if manifest.timeout > 0 {
+		return manifest.timeout
+	}
+	return s.defaultBotTimeout
+}
@@ -0,0 +1,217 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/pb"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// cpuPeriod é o período (em microssegundos) usado no cpu.max do cgroup.
+const cpuPeriod = 100000
+
+// rlimitWrapper roda no próprio interpretador do bot, antes do entrypoint:
+// aplica os rlimits e faz exec do comando original, que os herda. Assim o
+// bot e os filhos dele nunca rodam sem os limites.
+const rlimitWrapper = `import os, resource, sys
+limit_as, limit_nofile = int(sys.argv[1]), int(sys.argv[2])
+if limit_as:
+    resource.setrlimit(resource.RLIMIT_AS, (limit_as, limit_as))
+if limit_nofile:
+    resource.setrlimit(resource.RLIMIT_NOFILE, (limit_nofile, limit_nofile))
+os.execv(sys.executable, [sys.executable] + sys.argv[3:])
+`
+
+// limitEnforcer aplica resourceLimits a um processo. Com um cgroup pai
+// delegado (BOT_CGROUP_PARENT, cgroups v2), memória, CPU e processos são
+// limitados pelo cgroup; sem ele, só há rlimits: memória vira RLIMIT_AS e
+// CPU e processos não são limitados. Arquivos abertos usam sempre
+// RLIMIT_NOFILE.
+type limitEnforcer struct {
+	limits    resourceLimits
+	cgroupDir string
+	cgroupFD  int
+	// limitAS indica que a memória é limitada por RLIMIT_AS.
+	limitAS bool
+	// hit é o limite que a saída do bot indicou ter sido atingido.
+	hit string
+	// notes descreve o que foi (ou não) aplicado, para o log do job.
+	notes []string
+}
+
+// prepareLimits configura cmd, que deve executar um interpretador Python,
+// antes do Start. Deve ser chamado depois de bindProcessTree, que define o
+// SysProcAttr.
+func prepareLimits(cmd *exec.Cmd, jobID string, limits resourceLimits, cgroupParent string) (*limitEnforcer, error) {
+	e := &limitEnforcer{limits: limits, cgroupFD: -1}
+	if limits.MemoryBytes > 0 || limits.CPUs > 0 || limits.Processes > 0 {
+		if cgroupParent != "" {
+			if err := e.setupCgroup(cmd, cgroupParent, jobID); err != nil {
+				e.close()
+				return nil, err
+			}
+			e.notes = append(e.notes, "Limites aplicados via cgroup "+e.cgroupDir)
+		} else {
+			e.limitAS = limits.MemoryBytes > 0
+			if limits.MemoryBytes > 0 {
+				e.notes = append(e.notes, "Memória limitada via RLIMIT_AS (espaço de endereçamento); configure BOT_CGROUP_PARENT para usar cgroups v2.")
+			}
+			if limits.CPUs > 0 {
+				e.notes = append(e.notes, "Limite de CPU ignorado: requer BOT_CGROUP_PARENT (cgroups v2).")
+			}
+			if limits.Processes > 0 {
+				e.notes = append(e.notes, "Limite de processos ignorado: requer BOT_CGROUP_PARENT (cgroups v2).")
+			}
+		}
+	}
+
+	var limitAS, limitNofile uint64
+	if e.limitAS {
+		limitAS = uint64(limits.MemoryBytes)
+		if err := checkRlimit(unix.RLIMIT_AS, limitAS, "memória"); err != nil {
+			e.close()
+			return nil, err
+		}
+	}
+	if limits.OpenFiles > 0 {
+		limitNofile = uint64(limits.OpenFiles)
+		if err := checkRlimit(unix.RLIMIT_NOFILE, limitNofile, "arquivos abertos"); err != nil {
+			e.close()
+			return nil, err
+		}
+	}
+	if limitAS > 0 || limitNofile > 0 {
+		cmd.Args = append([]string{cmd.Args[0], "-c", rlimitWrapper,
+			strconv.FormatUint(limitAS, 10), strconv.FormatUint(limitNofile, 10)}, cmd.Args[1:]...)
+	}
+	return e, nil
+}
+
+// checkRlimit confere se o bot poderá receber o limite: um processo sem
+// privilégios não passa do máximo (hard limit) que herdou do agente.
+func checkRlimit(resource int, limit uint64, name string) error {
+	var current unix.Rlimit
+	if err := unix.Getrlimit(resource, &current); err != nil {
+		return fmt.Errorf("erro ao consultar o limite de %s do agente: %v", name, err)
+	}
+	if current.Max != unix.RLIM_INFINITY && limit > current.Max {
+		return fmt.Errorf("limite de %s %d acima do máximo do agente (%d)", name, limit, current.Max)
+	}
+	return nil
+}
+
+func (e *limitEnforcer) setupCgroup(cmd *exec.Cmd, parent, jobID string) error {
+	dir := filepath.Join(parent, "gobot-"+jobID)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar o cgroup do bot: %v", err)
+	}
+	e.cgroupDir = dir
+
+	write := func(file, value string) error {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(value), 0644); err != nil {
+			return fmt.Errorf("erro ao configurar %s no cgroup (o controlador está habilitado em %s/cgroup.subtree_control?): %v", file, parent, err)
+		}
+		return nil
+	}
+	if e.limits.MemoryBytes > 0 {
+		if err := write("memory.max", strconv.FormatInt(e.limits.MemoryBytes, 10)); err != nil {
+			return err
+		}
+		// Sem swap o limite de memória é de fato o limite; nem todo kernel
+		// tem o arquivo.
+		os.WriteFile(filepath.Join(dir, "memory.swap.max"), []byte("0"), 0644)
+	}
+	if e.limits.CPUs > 0 {
+		quota := int64(e.limits.CPUs * cpuPeriod)
+		if err := write("cpu.max", fmt.Sprintf("%d %d", quota, cpuPeriod)); err != nil {
+			return err
+		}
+	}
+	if e.limits.Processes > 0 {
+		if err := write("pids.max", strconv.FormatInt(e.limits.Processes, 10)); err != nil {
+			return err
+		}
+	}
+
+	fd, err := syscall.Open(dir, syscall.O_DIRECTORY|syscall.O_RDONLY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir o cgroup do bot: %v", err)
+	}
+	e.cgroupFD = fd
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	// O processo já nasce dentro do cgroup, sem janela em que rode sem limites.
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+	return nil
+}
+
+// started fecha o cgroup do processo recém-criado, que já nasceu dentro dele.
+func (e *limitEnforcer) started(pid int) error {
+	if e.cgroupFD >= 0 {
+		syscall.Close(e.cgroupFD)
+		e.cgroupFD = -1
+	}
+	return nil
+}
+
+// observe recebe as linhas de saída do bot. Com rlimits quem termina é o
+// próprio Python, com MemoryError ou "[Errno 24] Too many open files" no
+// stderr, que é a única pista de que o limite foi atingido.
+func (e *limitEnforcer) observe(source pb.LogSource, line outputLine) {
+	if source != pb.LogSource_LOG_SOURCE_STDERR {
+		return
+	}
+	switch {
+	case e.limitAS && strings.HasPrefix(line.text, "MemoryError"):
+		e.hit = limitMemory
+	case e.limits.OpenFiles > 0 && strings.Contains(line.text, "[Errno 24]"):
+		e.hit = limitOpenFiles
+	}
+}
+
+// exceeded informa se o bot falhou por exceder um limite: pelo OOM killer do
+// cgroup ou, com rlimits, pelo erro que o Python escreveu no stderr. Deve ser
+// chamado depois de toda a saída ter sido lida.
+func (e *limitEnforcer) exceeded() string {
+	if e.cgroupDir != "" {
+		data, _ := os.ReadFile(filepath.Join(e.cgroupDir, "memory.events"))
+		for _, line := range strings.Split(string(data), "\n") {
+			if key, value, ok := strings.Cut(line, " "); ok && key == "oom_kill" && value != "0" {
+				return limitMemory
+			}
+		}
+	}
+	return e.hit
+}
+
+// close encerra o que tiver sobrado no cgroup e o remove.
+func (e *limitEnforcer) close() {
+	if e.cgroupFD >= 0 {
+		syscall.Close(e.cgroupFD)
+		e.cgroupFD = -1
+	}
+	if e.cgroupDir == "" {
+		return
+	}
+	// cgroup.kill (kernel 5.14+) pega também os processos que saíram do
+	// grupo de processos do bot.
+	os.WriteFile(filepath.Join(e.cgroupDir, "cgroup.kill"), []byte("1"), 0644)
+	for i := 0; i < 50; i++ {
+		if err := os.Remove(e.cgroupDir); err == nil || os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	fmt.Printf("não foi possível remover o cgroup %s\n", e.cgroupDir)
+}
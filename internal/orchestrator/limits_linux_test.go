@@ -0,0 +1,93 @@
+//go:build linux
+
+package orchestrator
+
+import (
+	"context"
+	"orchestrator/pb"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+// Os rlimits valem desde a primeira instrução do bot e passam para os filhos.
+func TestPrepareLimitsRlimits(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 indisponível")
+	}
+	script := `import resource, subprocess, sys
+print(resource.getrlimit(resource.RLIMIT_NOFILE)[1], resource.getrlimit(resource.RLIMIT_AS)[1])
+sys.stdout.flush()
+subprocess.run(["sh", "-c", "ulimit -Hn"])
+print(sys.argv[1:])`
+	cmd := exec.CommandContext(context.Background(), python, "-c", script, "a", "b c")
+	bindProcessTree(cmd)
+	limits := resourceLimits{MemoryBytes: 1 << 30, OpenFiles: 64}
+	enforcer, err := prepareLimits(cmd, "0123456789abcdef", limits, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer enforcer.close()
+
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%v: %s", err, out)
+	}
+	want := "64 1073741824\n64\n['a', 'b c']\n"
+	if string(out) != want {
+		t.Fatalf("saída = %q, esperado %q", out, want)
+	}
+}
+
+func TestPrepareLimitsAboveAgentMax(t *testing.T) {
+	var current unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &current); err != nil {
+		t.Fatal(err)
+	}
+	if current.Max == unix.RLIM_INFINITY {
+		t.Skip("RLIMIT_NOFILE do agente é ilimitado")
+	}
+	cmd := exec.Command("python3", "bot.py")
+	_, err := prepareLimits(cmd, "0123456789abcdef", resourceLimits{OpenFiles: int64(current.Max) + 1}, "")
+	if err == nil || !strings.Contains(err.Error(), "acima do máximo do agente") {
+		t.Fatalf("erro = %v, esperado limite acima do máximo", err)
+	}
+	if len(cmd.Args) != 2 {
+		t.Fatalf("o comando foi alterado: %q", cmd.Args)
+	}
+}
+
+func TestLimitEnforcerExceeded(t *testing.T) {
+	stderr := pb.LogSource_LOG_SOURCE_STDERR
+	stdout := pb.LogSource_LOG_SOURCE_STDOUT
+	tests := []struct {
+		name   string
+		limits resourceLimits
+		source pb.LogSource
+		line   string
+		want   string
+	}{
+		{"MemoryError com RLIMIT_AS", resourceLimits{MemoryBytes: 1 << 30}, stderr, "MemoryError", limitMemory},
+		{"MemoryError com mensagem", resourceLimits{MemoryBytes: 1 << 30}, stderr, "MemoryError: out of memory", limitMemory},
+		{"MemoryError sem limite de memória", resourceLimits{OpenFiles: 64}, stderr, "MemoryError", ""},
+		{"arquivos abertos", resourceLimits{OpenFiles: 64}, stderr, "OSError: [Errno 24] Too many open files: 'x'", limitOpenFiles},
+		{"arquivos abertos sem limite", resourceLimits{MemoryBytes: 1 << 30}, stderr, "OSError: [Errno 24] Too many open files", ""},
+		{"stdout é ignorado", resourceLimits{MemoryBytes: 1 << 30}, stdout, "MemoryError", ""},
+		{"outra falha", resourceLimits{MemoryBytes: 1 << 30, OpenFiles: 64}, stderr, "ValueError: x", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enforcer, err := prepareLimits(exec.Command("python3", "bot.py"), "0123456789abcdef", tt.limits, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			enforcer.observe(tt.source, outputLine{text: tt.line})
+			if got := enforcer.exceeded(); got != tt.want {
+				t.Fatalf("exceeded() = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
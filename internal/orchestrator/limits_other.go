@@ -0,0 +1,30 @@
+//go:build !linux
+
+package orchestrator
+
+import (
+	"orchestrator/pb"
+	"os/exec"
+)
+
+// limitEnforcer fora do Linux não aplica nada; os limites ficam só
+// registrados no log do job.
+type limitEnforcer struct {
+	notes []string
+}
+
+func prepareLimits(cmd *exec.Cmd, jobID string, limits resourceLimits, cgroupParent string) (*limitEnforcer, error) {
+	e := &limitEnforcer{}
+	if !limits.empty() {
+		e.notes = append(e.notes, "Limites de recursos só são aplicados no Linux; ignorando: "+limits.String())
+	}
+	return e, nil
+}
+
+func (e *limitEnforcer) started(pid int) error { return nil }
+
+func (e *limitEnforcer) observe(source pb.LogSource, line outputLine) {}
+
+func (e *limitEnforcer) exceeded() string { return "" }
+
+func (e *limitEnforcer) close() {}
@@ -0,0 +1,294 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"orchestrator/pb"
+	"os/exec"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// lineOptions controla como a saída de um processo vira linhas de log.
+type lineOptions struct {
+	maxBytes  int           // o que passar disso numa linha é descartado, com um aviso no fim
+	idleFlush time.Duration // sem saída por esse tempo, o trecho sem quebra de linha é enviado
+	charset   outputCharset
+}
+
+// outputLine é uma linha lida da saída de um processo, já em UTF-8 e com o
+// aviso de truncamento.
+type outputLine struct {
+	text string
+	// raw são os bytes originais, sem o aviso, quando text não os reproduz.
+	raw      []byte
+	partial  bool
+	progress bool
+}
+
+// lineReader quebra a saída de um processo em linhas. Diferente do
+// bufio.Scanner, não desiste de linhas grandes (corta e avisa), envia o que
+// ficou sem quebra de linha quando o processo para de escrever e trata as
+// reescritas com \r como atualizações de progresso, enviando no máximo uma a
+// cada idleFlush.
+type lineReader struct {
+	opts lineOptions
+	emit func(outputLine)
+
+	buf       []byte
+	truncated int
+	cr        bool // o último byte foi \r: ainda não se sabe se é \r\n ou reescrita
+	rewritten bool // a linha atual já foi reescrita com \r
+	flushed   bool // parte da linha atual já foi enviada como parcial
+	sent      bool // buf já foi enviado como progresso e não mudou desde então
+
+	state        outputLine // último estado de progresso
+	pending      bool       // state ainda não foi enviado
+	lastProgress time.Time
+}
+
+func newLineReader(opts lineOptions, emit func(outputLine)) *lineReader {
+	if opts.maxBytes < 1 {
+		opts.maxBytes = 64 * 1024
+	}
+	if opts.idleFlush <= 0 {
+		opts.idleFlush = time.Second
+	}
+	return &lineReader{opts: opts, emit: emit}
+}
+
+// run lê src até EOF ou erro e envia o que restou.
+func (r *lineReader) run(src io.Reader) {
+	chunks := make(chan []byte)
+	consumed := make(chan struct{})
+	go func() {
+		defer close(chunks)
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := src.Read(buf)
+			if n > 0 {
+				chunks <- buf[:n]
+				<-consumed
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	idle := time.NewTimer(r.opts.idleFlush)
+	defer idle.Stop()
+	for {
+		select {
+		case chunk, ok := <-chunks:
+			if !ok {
+				r.finish()
+				return
+			}
+			r.write(chunk)
+			consumed <- struct{}{}
+			idle.Reset(r.opts.idleFlush)
+		case <-idle.C:
+			r.flushIdle()
+		}
+	}
+}
+
+func (r *lineReader) write(chunk []byte) {
+	for _, b := range chunk {
+		if r.cr {
+			r.cr = false
+			if b == '\n' {
+				r.endLine()
+				continue
+			}
+			r.rewrite()
+		}
+		switch b {
+		case '\n':
+			r.endLine()
+		case '\r':
+			r.cr = true
+		default:
+			if len(r.buf) < r.opts.maxBytes {
+				r.buf = append(r.buf, b)
+			} else {
+				r.truncated++
+			}
+			r.sent = false
+		}
+	}
+}
+
+// rewrite trata um \r que não faz parte de um \r\n: o que foi escrito até ali
+// é um estado de progresso e o que vier depois o substitui.
+func (r *lineReader) rewrite() {
+	r.rewritten = true
+	if len(r.buf) > 0 && !r.sent {
+		r.state = r.line(true)
+		r.pending = true
+	}
+	r.reset()
+	if time.Since(r.lastProgress) >= r.opts.idleFlush {
+		r.sendProgress()
+	}
+}
+
+func (r *lineReader) sendProgress() {
+	if !r.pending {
+		return
+	}
+	r.pending = false
+	r.lastProgress = time.Now()
+	r.emit(r.state)
+}
+
+func (r *lineReader) endLine() {
+	switch {
+	case r.rewritten && len(r.buf) == 0:
+		// "50%\r100%\r" seguido de \n: vale o último estado.
+		r.sendProgress()
+	case r.rewritten:
+		if !r.sent {
+			r.emit(r.line(true))
+		}
+	case len(r.buf) > 0 || r.truncated > 0 || !r.flushed:
+		r.emit(r.line(false))
+	}
+	r.reset()
+	r.rewritten, r.flushed, r.pending = false, false, false
+}
+
+// flushIdle é chamado quando o processo fica idleFlush sem escrever.
+func (r *lineReader) flushIdle() {
+	if r.cr {
+		r.cr = false
+		r.rewrite()
+	}
+	// Um caractere UTF-8 cortado pela pausa fica para o próximo trecho; pela
+	// metade ele não seria UTF-8 válido e o trecho cairia no cp1252.
+	held := r.incompleteTail()
+	r.buf = r.buf[:len(r.buf)-len(held)]
+	defer func() { r.buf = append(r.buf, held...) }()
+
+	if r.rewritten {
+		// Barras que escrevem "\r50%" deixam o estado atual sem \r depois
+		// dele; ele continua no buffer porque a linha ainda pode crescer.
+		if len(r.buf) > 0 && !r.sent {
+			r.state = r.line(true)
+			r.pending = true
+		}
+		r.sendProgress()
+		r.sent = len(r.buf) > 0
+		return
+	}
+	if len(r.buf) > 0 || r.truncated > 0 {
+		line := r.line(false)
+		line.partial = true
+		r.emit(line)
+		r.reset()
+		r.flushed = true
+	}
+}
+
+// incompleteTail devolve uma cópia dos bytes do fim de buf que começam um
+// caractere UTF-8 ainda incompleto.
+func (r *lineReader) incompleteTail() []byte {
+	if r.truncated > 0 || !r.opts.charset.multibyte() {
+		return nil
+	}
+	for i := len(r.buf) - 1; i >= 0 && len(r.buf)-i < utf8.UTFMax; i-- {
+		if utf8.RuneStart(r.buf[i]) {
+			if utf8.FullRune(r.buf[i:]) {
+				return nil
+			}
+			return bytes.Clone(r.buf[i:])
+		}
+	}
+	return nil
+}
+
+func (r *lineReader) finish() {
+	if r.cr {
+		r.cr = false
+		r.rewrite()
+	}
+	if len(r.buf) > 0 || r.truncated > 0 || r.pending {
+		r.endLine()
+	}
+}
+
+func (r *lineReader) reset() {
+	r.buf = r.buf[:0]
+	r.truncated = 0
+	r.sent = false
+}
+
+// line monta a linha atual. Em UTF-8, um corte no meio de um caractere recua
+// até o início dele, para o aviso não vir depois de um byte inválido.
+func (r *lineReader) line(progress bool) outputLine {
+	raw, truncated := r.buf, r.truncated
+	if truncated > 0 && r.opts.charset.multibyte() {
+		i := len(raw) - 1
+		for i > 0 && len(raw)-i < utf8.UTFMax && !utf8.RuneStart(raw[i]) {
+			i--
+		}
+		if i >= 0 && !utf8.FullRune(raw[i:]) {
+			truncated += len(raw) - i
+			raw = raw[:i]
+		}
+	}
+	text, exact := r.opts.charset.decode(raw)
+	line := outputLine{text: text, progress: progress}
+	if !exact {
+		line.raw = bytes.Clone(raw)
+	}
+	if truncated > 0 {
+		line.text += fmt.Sprintf(" … [linha truncada: %d bytes descartados]", truncated)
+	}
+	return line
+}
+
+// attachOutput liga o stdout e o stderr de cmd ao log como linhas da fase
+// phase, decodificadas de charset; onLine, se não for nil, também recebe cada
+// linha. Deve ser chamada antes de Start, e a função devolvida depois de Wait
+// (ou de uma falha em Start): ela fecha os pipes e espera os leitores
+// enviarem o que restou.
+func (s *logSink) attachOutput(cmd *exec.Cmd, phase pb.DeployPhase, charset outputCharset, onLine func(pb.LogSource, outputLine)) (wait func()) {
+	opts := s.lines
+	opts.charset = charset
+	var wg sync.WaitGroup
+	var writers []*io.PipeWriter
+	attach := func(source pb.LogSource) io.Writer {
+		pr, pw := io.Pipe()
+		writers = append(writers, pw)
+		reader := newLineReader(opts, func(line outputLine) {
+			if onLine != nil {
+				onLine(source, line)
+			}
+			msg := newOutputLog(phase, source, line.text)
+			msg.Partial = line.partial
+			msg.Progress = line.progress
+			s.sendOutput(msg, line.raw)
+		})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reader.run(pr)
+		}()
+		return pw
+	}
+	// Com um io.Writer no lugar do pipe, o Wait só volta depois de copiar
+	// toda a saída (ou de WaitDelay), então nada se perde por ler depois.
+	cmd.Stdout = attach(pb.LogSource_LOG_SOURCE_STDOUT)
+	cmd.Stderr = attach(pb.LogSource_LOG_SOURCE_STDERR)
+
+	return func() {
+		for _, w := range writers {
+			w.Close()
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,204 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// step é uma escrita na saída do processo ou, com idle, uma pausa maior que
+// idleFlush.
+type step struct {
+	data string
+	idle bool
+}
+
+func readLines(opts lineOptions, steps ...step) []outputLine {
+	var lines []outputLine
+	r := newLineReader(opts, func(line outputLine) {
+		lines = append(lines, line)
+	})
+	for _, st := range steps {
+		if st.idle {
+			r.flushIdle()
+			continue
+		}
+		r.write([]byte(st.data))
+	}
+	r.finish()
+	return lines
+}
+
+func TestLineReader(t *testing.T) {
+	// Com idleFlush longo, só o primeiro estado de progresso sai antes do fim
+	// da linha; os intermediários são descartados.
+	opts := lineOptions{maxBytes: 8, idleFlush: time.Hour}
+	idle := step{idle: true}
+
+	tests := []struct {
+		name  string
+		steps []step
+		want  []outputLine
+	}{
+		{
+			name:  "linhas com \\n e \\r\\n",
+			steps: []step{{data: "a\nb\r\nc"}},
+			want:  []outputLine{{text: "a"}, {text: "b"}, {text: "c"}},
+		},
+		{
+			name:  "linha vazia",
+			steps: []step{{data: "\n\n"}},
+			want:  []outputLine{{text: ""}, {text: ""}},
+		},
+		{
+			name:  "escrita dividida no \\r\\n",
+			steps: []step{{data: "abc\r"}, {data: "\ndef\n"}},
+			want:  []outputLine{{text: "abc"}, {text: "def"}},
+		},
+		{
+			name:  "linha longa é truncada",
+			steps: []step{{data: "0123456789ab\n"}},
+			want:  []outputLine{{text: "01234567 … [linha truncada: 4 bytes descartados]"}},
+		},
+		{
+			name:  "corte não divide caractere UTF-8",
+			steps: []step{{data: "abcdefgé\n"}},
+			want:  []outputLine{{text: "abcdefg … [linha truncada: 2 bytes descartados]"}},
+		},
+		{
+			name:  "progresso com \\r",
+			steps: []step{{data: "10%\r50%\r100%\n"}},
+			want:  []outputLine{{text: "10%", progress: true}, {text: "100%", progress: true}},
+		},
+		{
+			name:  "progresso terminado em \\r antes do \\n",
+			steps: []step{{data: "10%\r50%\r100%\r\n"}},
+			want:  []outputLine{{text: "10%", progress: true}, {text: "100%", progress: true}},
+		},
+		{
+			name:  "trecho sem quebra de linha sai como parcial",
+			steps: []step{{data: "senha: "}, idle, {data: "\n"}},
+			want:  []outputLine{{text: "senha: ", partial: true}},
+		},
+		{
+			name:  "parcial seguido do resto da linha",
+			steps: []step{{data: "abc"}, idle, {data: "def\n"}},
+			want:  []outputLine{{text: "abc", partial: true}, {text: "def"}},
+		},
+		{
+			name:  "caractere UTF-8 dividido pela pausa",
+			steps: []step{{data: "ol\xc3"}, idle, {data: "\xa1 mundo\n"}},
+			want:  []outputLine{{text: "ol", partial: true}, {text: "á mundo"}},
+		},
+		{
+			name:  "pausa no meio do único caractere",
+			steps: []step{{data: "\xe2\x9c"}, idle, {data: "\x93\n"}},
+			want:  []outputLine{{text: "✓"}},
+		},
+		{
+			name:  "progresso dividido no meio de um caractere",
+			steps: []step{{data: "\r10% \xe2"}, idle, {data: "\x96\x88\r"}, idle},
+			want:  []outputLine{{text: "10% ", progress: true}, {text: "10% █", progress: true}},
+		},
+		{
+			name:  "progresso sem \\r no fim é enviado na pausa",
+			steps: []step{{data: "\r10%"}, idle, {data: "\r20%"}, idle},
+			want:  []outputLine{{text: "10%", progress: true}, {text: "20%", progress: true}},
+		},
+		{
+			name:  "o que resta no fim é enviado",
+			steps: []step{{data: "a\nb"}},
+			want:  []outputLine{{text: "a"}, {text: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readLines(opts, tt.steps...)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("linhas = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineReaderCharset(t *testing.T) {
+	auto, err := parseOutputCharset("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	latin1, err := parseOutputCharset("latin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		charset outputCharset
+		data    string
+		want    outputLine
+	}{
+		{"utf-8 válido", auto, "café\n", outputLine{text: "café"}},
+		{"auto cai para cp1252", auto, "caf\xe9\n", outputLine{text: "café", raw: []byte("caf\xe9")}},
+		{"latin1", latin1, "a\xe7\xe3o\n", outputLine{text: "ação", raw: []byte("a\xe7\xe3o")}},
+		{"latin1 em ASCII", latin1, "acao\n", outputLine{text: "acao"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readLines(lineOptions{charset: tt.charset}, step{data: tt.data})
+			if len(got) != 1 || !reflect.DeepEqual(got[0], tt.want) {
+				t.Fatalf("linhas = %+v, esperado [%+v]", got, tt.want)
+			}
+		})
+	}
+}
+
+// Em codificações de um byte por caractere não há o que segurar na pausa.
+func TestLineReaderIdleSingleByte(t *testing.T) {
+	latin1, err := parseOutputCharset("latin1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readLines(lineOptions{charset: latin1, idleFlush: time.Hour}, step{data: "caf\xc3"}, step{idle: true})
+	want := []outputLine{{text: "cafÃ", raw: []byte("caf\xc3"), partial: true}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("linhas = %+v, esperado %+v", got, want)
+	}
+}
+
+func TestLineReaderRun(t *testing.T) {
+	var lines []string
+	r := newLineReader(lineOptions{}, func(line outputLine) {
+		lines = append(lines, line.text)
+	})
+	long := strings.Repeat("x", 100*1024)
+	r.run(strings.NewReader("um\n" + long + "\ndois"))
+
+	want := []string{
+		"um",
+		long[:64*1024] + fmt.Sprintf(" … [linha truncada: %d bytes descartados]", 36*1024),
+		"dois",
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Fatalf("run enviou %d linhas, esperado %d", len(lines), len(want))
+	}
+}
+
+// raw não pode apontar para o buffer do leitor, que é reaproveitado na
+// linha seguinte.
+func TestLineReaderRawIsCopy(t *testing.T) {
+	var lines []outputLine
+	r := newLineReader(lineOptions{}, func(line outputLine) {
+		lines = append(lines, line)
+	})
+	r.write([]byte("caf\xe9\nxyz\xe9\n"))
+	if len(lines) != 2 {
+		t.Fatalf("%d linhas, esperado 2", len(lines))
+	}
+	if !bytes.Equal(lines[0].raw, []byte("caf\xe9")) {
+		t.Fatalf("raw da primeira linha = %q, esperado %q", lines[0].raw, "caf\xe9")
+	}
+}
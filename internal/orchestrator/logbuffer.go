@@ -0,0 +1,236 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/pb"
+	"sync"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jobLog guarda as últimas linhas produzidas por um job em um anel de
+// tamanho fixo, para que qualquer cliente possa reproduzi-las e depois
+// acompanhar a saída ao vivo. Um leitor que fica para trás recebe um aviso
+// com quantas linhas perdeu e continua da mais antiga ainda guardada; o log
+// completo fica no arquivo do job. Quando o job termina, o anel é liberado e
+// quem ainda estiver lendo continua pelo arquivo.
+type jobLog struct {
+	mu     sync.Mutex
+	jobID  string
+	ring   []*pb.LogResponse
+	next   int64 // sequence da próxima linha
+	closed bool
+	notify chan struct{}
+}
+
+// errLogReleased indica que o anel foi liberado antes de o leitor chegar ao
+// fim do log; o restante está no arquivo do job.
+var errLogReleased = errors.New("log do job liberado da memória")
+
+func newJobLog(jobID string, capacity int) *jobLog {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &jobLog{
+		jobID:  jobID,
+		ring:   make([]*pb.LogResponse, capacity),
+		notify: make(chan struct{}),
+	}
+}
+
+// append numera msg e a guarda no lugar da linha mais antiga quando o anel
+// está cheio. Nunca espera por leitores.
+func (l *jobLog) append(msg *pb.LogResponse) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return false
+	}
+	msg.Sequence = l.next
+	l.ring[l.next%int64(len(l.ring))] = msg
+	l.next++
+	l.broadcast()
+	return true
+}
+
+// close marca o fim do log; leitores recebem o restante e encerram. Com
+// release, o anel é descartado, o que só deve ser feito quando o log completo
+// estiver no arquivo.
+func (l *jobLog) close(release bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	l.closed = true
+	if release {
+		l.ring = nil
+	}
+	l.broadcast()
+}
+
+func (l *jobLog) broadcast() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// read devolve as linhas a partir de offset, quantas linhas a partir de
+// offset já saíram do anel, se o log já terminou e um canal que é fechado
+// quando houver novidades.
+func (l *jobLog) read(offset int64) ([]*pb.LogResponse, int64, bool, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.ring == nil {
+		return nil, 0, l.closed, l.notify
+	}
+	var dropped int64
+	if oldest := l.next - int64(len(l.ring)); offset < oldest {
+		dropped = oldest - offset
+		offset = oldest
+	}
+	var lines []*pb.LogResponse
+	for seq := offset; seq < l.next; seq++ {
+		lines = append(lines, l.ring[seq%int64(len(l.ring))])
+	}
+	return lines, dropped, l.closed, l.notify
+}
+
+// overflowMarker avisa o leitor de que dropped linhas, até a de sequence
+// last, foram descartadas antes que ele as lesse.
+func (l *jobLog) overflowMarker(last, dropped int64) *pb.LogResponse {
+	return &pb.LogResponse{
+		JobId:     l.jobID,
+		Line:      fmt.Sprintf("%d linha(s) descartada(s) do buffer antes de serem lidas; o log completo continua disponível em GetJobLogs.", dropped),
+		Source:    pb.LogSource_LOG_SOURCE_AGENT,
+		Status:    pb.LogStatus_LOG_STATUS_INFO,
+		Timestamp: timestamppb.Now(),
+		Sequence:  last,
+		Dropped:   dropped,
+	}
+}
+
+// end devolve a sequence da próxima linha, que não muda depois de close.
+func (l *jobLog) end() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.next
+}
+
+// follow envia as linhas a partir de offset e continua enviando as novas até
+// o log ser fechado ou ctx ser cancelado. Devolve o offset da próxima linha
+// e errLogReleased se o anel foi liberado antes do fim.
+func (l *jobLog) follow(ctx context.Context, offset int64, send func(*pb.LogResponse) error) (int64, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	for {
+		lines, dropped, closed, notify := l.read(offset)
+		if dropped > 0 {
+			offset += dropped
+			if err := send(l.overflowMarker(offset-1, dropped)); err != nil {
+				return offset, err
+			}
+		}
+		for _, line := range lines {
+			if err := ctx.Err(); err != nil {
+				return offset, err
+			}
+			if err := send(line); err != nil {
+				return offset, err
+			}
+			offset = line.Sequence + 1
+		}
+		if closed && len(lines) == 0 {
+			if offset < l.end() {
+				return offset, errLogReleased
+			}
+			return offset, nil
+		}
+		if len(lines) > 0 {
+			continue
+		}
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return offset, ctx.Err()
+		}
+	}
+}
+
+// logSink é por onde as etapas de um job publicam o log. send não depende de
+// quem está lendo: a linha vai para o arquivo do job e para o anel, e cada
+// leitor a busca no seu ritmo, então um cliente lento ou desconectado não
+// trava o bot nem deixa goroutines esperando. Linhas enviadas depois de close
+// são descartadas.
+type logSink struct {
+	mu     sync.Mutex
+	job    *Job
+	file   *logWriter
+	lines  lineOptions
+	closed bool
+}
+
+// openLogSink abre o arquivo de log do job. Se ele não puder ser criado, o
+// job segue só com o anel em memória.
+func (s *OrchestratorService) openLogSink(job *Job) *logSink {
+	sink := &logSink{job: job, lines: s.lines}
+	file, err := openLogWriter(s.jobLogPath(job))
+	if err != nil {
+		sink.send(newLog(pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED, pb.LogStatus_LOG_STATUS_ERROR,
+			fmt.Sprintf("Log do job não será persistido: %v", err)))
+		return sink
+	}
+	sink.file = file
+	return sink
+}
+
+func (s *logSink) send(msg *pb.LogResponse) {
+	s.sendOutput(msg, nil)
+}
+
+// sendOutput é send para uma linha de saída de processo cujo texto não
+// reproduz os bytes originais: raw vai só para o arquivo do job.
+func (s *logSink) sendOutput(msg *pb.LogResponse, raw []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	msg.JobId = s.job.ID
+	msg.Line = s.job.redactor.redact(msg.Line)
+	if raw != nil {
+		raw = []byte(s.job.redactor.redact(string(raw)))
+	}
+	if msg.Source == pb.LogSource_LOG_SOURCE_UNSPECIFIED {
+		msg.Source = pb.LogSource_LOG_SOURCE_AGENT
+	}
+	if msg.Timestamp == nil {
+		msg.Timestamp = timestamppb.Now()
+	}
+	// O anel numera a linha; o arquivo é gravado em seguida, sob o mesmo
+	// lock, para manter a ordem das sequences.
+	s.job.logs.append(msg)
+	if s.file != nil {
+		if err := s.file.write(msg, raw); err != nil {
+			fmt.Printf("[%s] erro ao gravar log: %v\n", s.job.ID, err)
+		}
+	}
+}
+
+// close fecha o arquivo e o anel. O anel só é liberado se o arquivo guardou
+// o log; sem ele, continua em memória enquanto o job estiver no registro.
+func (s *logSink) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.file != nil {
+		s.file.close()
+	}
+	s.job.logs.close(s.file != nil)
+}
@@ -0,0 +1,220 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/pb"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func appendLines(l *jobLog, lines ...string) {
+	for _, line := range lines {
+		l.append(&pb.LogResponse{Line: line})
+	}
+}
+
+// describe resume as mensagens como "seq:linha", ou "seq:-N" para o aviso de
+// N linhas descartadas.
+func describe(msgs []*pb.LogResponse) []string {
+	var out []string
+	for _, msg := range msgs {
+		if msg.Dropped > 0 {
+			out = append(out, fmt.Sprintf("%d:-%d", msg.Sequence, msg.Dropped))
+			continue
+		}
+		out = append(out, fmt.Sprintf("%d:%s", msg.Sequence, msg.Line))
+	}
+	return out
+}
+
+func collect(msgs *[]*pb.LogResponse) func(*pb.LogResponse) error {
+	return func(msg *pb.LogResponse) error {
+		*msgs = append(*msgs, msg)
+		return nil
+	}
+}
+
+func TestJobLogFollow(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []string
+		release bool
+		offset  int64
+		want    []string
+		wantEnd int64
+		wantErr error
+	}{
+		{"do início", []string{"a", "b"}, false, 0, []string{"0:a", "1:b"}, 2, nil},
+		{"de um offset", []string{"a", "b", "c"}, false, 2, []string{"2:c"}, 3, nil},
+		{"leitor atrasado", []string{"a", "b", "c", "d", "e"}, false, 0, []string{"1:-2", "2:c", "3:d", "4:e"}, 5, nil},
+		{"offset depois do fim", []string{"a"}, false, 5, nil, 5, nil},
+		{"anel liberado", []string{"a", "b"}, true, 0, nil, 0, errLogReleased},
+		{"anel liberado depois do fim", []string{"a", "b"}, true, 2, nil, 2, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newJobLog("job", 3)
+			appendLines(l, tt.lines...)
+			l.close(tt.release)
+
+			var msgs []*pb.LogResponse
+			end, err := l.follow(context.Background(), tt.offset, collect(&msgs))
+			if !errors.Is(err, tt.wantErr) || end != tt.wantEnd {
+				t.Fatalf("follow = (%d, %v), esperado (%d, %v)", end, err, tt.wantEnd, tt.wantErr)
+			}
+			if got := describe(msgs); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("mensagens = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJobLogFollowLive(t *testing.T) {
+	l := newJobLog("job", 10)
+	appendLines(l, "a")
+
+	sent := make(chan *pb.LogResponse)
+	done := make(chan error, 1)
+	go func() {
+		_, err := l.follow(context.Background(), 0, func(msg *pb.LogResponse) error {
+			sent <- msg
+			return nil
+		})
+		done <- err
+	}()
+
+	next := func() string {
+		select {
+		case msg := <-sent:
+			return msg.Line
+		case <-time.After(5 * time.Second):
+			t.Fatal("follow não enviou a linha")
+			return ""
+		}
+	}
+	if line := next(); line != "a" {
+		t.Fatalf("linha = %q, esperado a", line)
+	}
+	appendLines(l, "b")
+	if line := next(); line != "b" {
+		t.Fatalf("linha = %q, esperado b", line)
+	}
+	l.close(false)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Um leitor que ainda não chegou ao fim quando o anel é liberado recebe o
+// offset de onde continuar pelo arquivo.
+func TestJobLogFollowReleasedMidway(t *testing.T) {
+	l := newJobLog("job", 10)
+	appendLines(l, "a", "b")
+
+	var msgs []*pb.LogResponse
+	end, err := l.follow(context.Background(), 0, func(msg *pb.LogResponse) error {
+		msgs = append(msgs, msg)
+		if msg.Line == "b" {
+			// O job termina enquanto o leitor ainda envia "b".
+			appendLines(l, "c")
+			l.close(true)
+		}
+		return nil
+	})
+	if !errors.Is(err, errLogReleased) || end != 2 {
+		t.Fatalf("follow = (%d, %v), esperado (2, errLogReleased)", end, err)
+	}
+	if got := describe(msgs); !reflect.DeepEqual(got, []string{"0:a", "1:b"}) {
+		t.Fatalf("mensagens = %q", got)
+	}
+}
+
+func TestJobLogFollowCancelled(t *testing.T) {
+	l := newJobLog("job", 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	if _, err := l.follow(ctx, 0, collect(new([]*pb.LogResponse))); !errors.Is(err, context.Canceled) {
+		t.Fatalf("erro = %v, esperado context.Canceled", err)
+	}
+}
+
+// Depois que o job termina, AttachLogs lê do arquivo o mesmo que o anel
+// enviaria, inclusive quando o job já saiu do registro.
+func TestAttachLogsReplaysFinishedJob(t *testing.T) {
+	s := newTestService(t, 1)
+	job, _ := newTestJob(s, "b1", "main")
+	sink := s.openLogSink(job)
+	sink.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO, "clonando"))
+	partial := newOutputLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogSource_LOG_SOURCE_STDOUT, "senha: ")
+	partial.Partial = true
+	sink.send(partial)
+	progress := newOutputLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogSource_LOG_SOURCE_STDERR, "50%")
+	progress.Progress = true
+	sink.send(progress)
+
+	// Um leitor ao vivo, antes do fim do job.
+	var live []*pb.LogResponse
+	if _, err := job.logs.follow(context.Background(), 0, func(msg *pb.LogResponse) error {
+		live = append(live, msg)
+		if msg.Progress {
+			s.finishJob(job, pb.JobState_JOB_STATE_FAILED, pb.FailureReason_FAILURE_REASON_BOT_EXIT, 1, errors.New("exit status 1"))
+			sink.send(s.summaryLog(job))
+			sink.close()
+		}
+		return nil
+	}); !errors.Is(err, errLogReleased) {
+		t.Fatalf("follow = %v, esperado errLogReleased", err)
+	}
+	want := append([]*pb.LogResponse(nil), live...)
+	if err := s.replayJobLog(context.Background(), job.ID, int64(len(live)), func(msg *pb.LogResponse) error {
+		want = append(want, msg)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 4 || want[3].GetSummary() == nil {
+		t.Fatalf("mensagens = %q, esperado 4 com o resumo no fim", describe(want))
+	}
+
+	for _, registered := range []bool{true, false} {
+		if !registered {
+			s.mu.Lock()
+			delete(s.jobs, job.ID)
+			s.mu.Unlock()
+		}
+		for _, offset := range []int64{0, 2} {
+			var got []*pb.LogResponse
+			if err := s.AttachLogs(context.Background(), job.ID, offset, collect(&got)); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want)-int(offset) {
+				t.Fatalf("registrado %v, offset %d: mensagens = %q", registered, offset, describe(got))
+			}
+			for i, msg := range got {
+				expected := want[int(offset)+i]
+				// O arquivo guarda o tempo em UTC; compara o instante.
+				if !msg.Timestamp.AsTime().Equal(expected.Timestamp.AsTime()) {
+					t.Fatalf("linha %d: timestamp %v, esperado %v", msg.Sequence, msg.Timestamp.AsTime(), expected.Timestamp.AsTime())
+				}
+				msg.Timestamp, expected = nil, cloneLog(expected)
+				expected.Timestamp = nil
+				if !proto.Equal(msg, expected) {
+					t.Fatalf("linha %d: %v, esperado %v", msg.Sequence, msg, expected)
+				}
+			}
+		}
+	}
+
+	if err := s.AttachLogs(context.Background(), "0123456789abcdef", 0, collect(new([]*pb.LogResponse))); !errors.Is(err, ErrJobNotFound) {
+		t.Fatalf("AttachLogs de job inexistente = %v, esperado ErrJobNotFound", err)
+	}
+}
+
+func cloneLog(msg *pb.LogResponse) *pb.LogResponse {
+	return proto.Clone(msg).(*pb.LogResponse)
+}
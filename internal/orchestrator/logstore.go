@@ -0,0 +1,257 @@
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"orchestrator/pb"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var jobIDPattern = regexp.MustCompile(`^[a-f0-9]{16}$`)
+
+// logRecord é o formato de cada linha (NDJSON) do arquivo de log de um job.
+type logRecord struct {
+	Time     time.Time `json:"time"`
+	Sequence int64     `json:"seq"`
+	Phase    string    `json:"phase,omitempty"`
+	Stream   string    `json:"stream"`
+	Status   string    `json:"status"`
+	Line     string    `json:"line"`
+	// Raw são os bytes originais da saída quando Line foi convertida de outra
+	// codificação ou tinha bytes inválidos (base64 no JSON).
+	Raw      []byte `json:"raw,omitempty"`
+	Partial  bool   `json:"partial,omitempty"`
+	Progress bool   `json:"progress,omitempty"`
+	// Summary é o resumo do job (protojson), presente só na última linha.
+	Summary json.RawMessage `json:"summary,omitempty"`
+}
+
+func sourceName(source pb.LogSource) string {
+	switch source {
+	case pb.LogSource_LOG_SOURCE_STDOUT:
+		return "stdout"
+	case pb.LogSource_LOG_SOURCE_STDERR:
+		return "stderr"
+	}
+	return "agent"
+}
+
+func sourceFromName(name string) pb.LogSource {
+	switch name {
+	case "stdout":
+		return pb.LogSource_LOG_SOURCE_STDOUT
+	case "stderr":
+		return pb.LogSource_LOG_SOURCE_STDERR
+	}
+	return pb.LogSource_LOG_SOURCE_AGENT
+}
+
+func statusName(status pb.LogStatus) string {
+	return strings.TrimPrefix(status.String(), "LOG_STATUS_")
+}
+
+func statusFromName(name string) pb.LogStatus {
+	return pb.LogStatus(pb.LogStatus_value["LOG_STATUS_"+name])
+}
+
+func phaseName(phase pb.DeployPhase) string {
+	if phase == pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED {
+		return ""
+	}
+	return strings.ToLower(strings.TrimPrefix(phase.String(), "DEPLOY_PHASE_"))
+}
+
+func phaseFromName(name string) pb.DeployPhase {
+	return pb.DeployPhase(pb.DeployPhase_value["DEPLOY_PHASE_"+strings.ToUpper(name)])
+}
+
+// jobLogsDir guarda os logs dos jobs, um diretório por bot. Fica fora dos
+// diretórios das versões para que a limpeza de disco e DeleteBotVersion não
+// apaguem o histórico; o "." no início não é aceito em bot_id.
+func (s *OrchestratorService) jobLogsDir() string {
+	return filepath.Join(s.botsDir, ".logs")
+}
+
+func (s *OrchestratorService) jobLogPath(job *Job) string {
+	return filepath.Join(s.jobLogsDir(), job.Bot.BotID, job.ID+".ndjson")
+}
+
+// logWriter grava o log de um job em disco, uma linha JSON por mensagem.
+type logWriter struct {
+	f   *os.File
+	enc *json.Encoder
+}
+
+func openLogWriter(path string) (*logWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório de logs: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	return &logWriter{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (w *logWriter) write(msg *pb.LogResponse, raw []byte) error {
+	record := logRecord{
+		Time:     msg.Timestamp.AsTime(),
+		Sequence: msg.Sequence,
+		Phase:    phaseName(msg.Phase),
+		Stream:   sourceName(msg.Source),
+		Status:   statusName(msg.Status),
+		Line:     msg.Line,
+		Raw:      raw,
+		Partial:  msg.Partial,
+		Progress: msg.Progress,
+	}
+	if summary := msg.GetSummary(); summary != nil {
+		data, err := protojson.Marshal(summary)
+		if err != nil {
+			return fmt.Errorf("erro ao serializar resumo do job: %v", err)
+		}
+		record.Summary = data
+	}
+	return w.enc.Encode(record)
+}
+
+func (w *logWriter) close() error {
+	return w.f.Close()
+}
+
+// findJobLogFile localiza o arquivo de log de um job e o bot a que ele
+// pertence, inclusive de jobs que não estão mais no registro em memória.
+func (s *OrchestratorService) findJobLogFile(id string) (path, botID string, err error) {
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	s.mu.Unlock()
+	if ok {
+		return s.jobLogPath(job), job.Bot.BotID, nil
+	}
+
+	if !jobIDPattern.MatchString(id) {
+		return "", "", ErrJobNotFound
+	}
+	// <bots>/.logs/<bot>/<job>.ndjson
+	if matches, _ := filepath.Glob(filepath.Join(s.jobLogsDir(), "*", id+".ndjson")); len(matches) > 0 {
+		return matches[0], filepath.Base(filepath.Dir(matches[0])), nil
+	}
+	// Versões antigas do agente gravavam em <bots>/<bot>/<versão>/logs/<job>.ndjson.
+	if matches, _ := filepath.Glob(filepath.Join(s.botsDir, "*", "*", "logs", id+".ndjson")); len(matches) > 0 {
+		return matches[0], filepath.Base(filepath.Dir(filepath.Dir(filepath.Dir(matches[0])))), nil
+	}
+	return "", "", ErrJobNotFound
+}
+
+// readJobLog lê o log persistido do job e chama each para cada registro.
+func (s *OrchestratorService) readJobLog(id string, each func(logRecord) error) error {
+	path, _, err := s.findJobLogFile(id)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ErrJobNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao abrir arquivo de log: %v", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	for {
+		data, readErr := reader.ReadBytes('\n')
+		if len(data) > 0 {
+			var record logRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("arquivo de log corrompido: %v", err)
+			}
+			if err := each(record); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return fmt.Errorf("erro ao ler arquivo de log: %v", readErr)
+		}
+	}
+}
+
+// GetJobLogs lê o log persistido do job e envia cada linha com send.
+func (s *OrchestratorService) GetJobLogs(id string, send func(*pb.LogEntry) error) error {
+	return s.readJobLog(id, func(record logRecord) error {
+		return send(&pb.LogEntry{
+			Time:     timestamppb.New(record.Time),
+			Sequence: record.Sequence,
+			Phase:    phaseFromName(record.Phase),
+			Source:   sourceFromName(record.Stream),
+			Status:   statusFromName(record.Status),
+			Line:     record.Line,
+			Raw:      record.Raw,
+		})
+	})
+}
+
+// loggedSummary devolve o resumo gravado no log de um job finalizado, ou nil
+// se o log não tem resumo (o agente parou no meio do job).
+func (s *OrchestratorService) loggedSummary(id string) (*pb.JobSummary, error) {
+	var last json.RawMessage
+	err := s.readJobLog(id, func(record logRecord) error {
+		if len(record.Summary) > 0 {
+			last = record.Summary
+		}
+		return nil
+	})
+	if err != nil || last == nil {
+		return nil, err
+	}
+	summary := &pb.JobSummary{}
+	if err := protojson.Unmarshal(last, summary); err != nil {
+		return nil, fmt.Errorf("arquivo de log corrompido: %v", err)
+	}
+	return summary, nil
+}
+
+// replayJobLog reenvia, a partir de offset, o log de um job que já terminou
+// com as mesmas mensagens que AttachLogs mandaria ao vivo.
+func (s *OrchestratorService) replayJobLog(ctx context.Context, id string, offset int64, send func(*pb.LogResponse) error) error {
+	return s.readJobLog(id, func(record logRecord) error {
+		if record.Sequence < offset {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msg := &pb.LogResponse{
+			Line:      record.Line,
+			JobId:     id,
+			Source:    sourceFromName(record.Stream),
+			Status:    statusFromName(record.Status),
+			Phase:     phaseFromName(record.Phase),
+			Timestamp: timestamppb.New(record.Time),
+			Sequence:  record.Sequence,
+			Partial:   record.Partial,
+			Progress:  record.Progress,
+		}
+		if len(record.Summary) > 0 {
+			summary := &pb.JobSummary{}
+			if err := protojson.Unmarshal(record.Summary, summary); err != nil {
+				return fmt.Errorf("arquivo de log corrompido: %v", err)
+			}
+			msg.Terminal = &pb.LogResponse_Summary{Summary: summary}
+		}
+		return send(msg)
+	})
+}
@@ -0,0 +1,292 @@
+package orchestrator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"orchestrator/internal/config"
+	"orchestrator/internal/validation"
+	"orchestrator/pb"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// manifestFile é o manifesto opcional na raiz do repositório do bot, ex:
+//
+//	entrypoint: run.py
+//	args: ["--headless"]
+//	python: "3.11"
+//	dependencies: pyproject.toml
+//	workdir: src
+//	timeout: 30m
+//	env:
+//	  AMBIENTE: producao
+//	secrets: [API_KEY]
+//	output_encoding: cp1252
+//	resources:
+//	  memory: 512M
+//	  cpu: 1
+//	  processes: 64
+//	  open_files: 1024
+const manifestFile = "bot.yaml"
+
+var (
+	pythonVersionPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+){0,2}$`)
+	// unknownFieldPattern reescreve o erro do yaml.v3 para campos que não
+	// existem no manifesto, que cita o tipo Go.
+	unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type \S+`)
+)
+
+// botManifest descreve como instalar e executar um bot. Sem bot.yaml, vale
+// defaultManifest: main.py na raiz e requirements.txt, se existir.
+type botManifest struct {
+	// Entrypoint é relativo a Workdir.
+	Entrypoint string   `yaml:"entrypoint"`
+	Args       []string `yaml:"args"`
+	// Python é a versão exigida do interpretador (ex: "3.11").
+	Python string `yaml:"python"`
+	// Dependencies é relativo à raiz do repositório: requirements.txt (ou
+	// outro .txt), pyproject.toml ou Pipfile.
+	Dependencies string `yaml:"dependencies"`
+	// Workdir é relativo à raiz do repositório.
+	Workdir string `yaml:"workdir"`
+	Timeout string `yaml:"timeout"`
+	// Env são valores padrão; o env do DeployRequest tem precedência.
+	Env map[string]string `yaml:"env"`
+	// Secrets são variáveis que precisam vir de secret_refs.
+	Secrets []string `yaml:"secrets"`
+	// Resources só podem apertar os limites configurados no agente.
+	Resources manifestResources `yaml:"resources"`
+	// OutputEncoding é a codificação do stdout/stderr do bot: auto (padrão),
+	// utf-8, cp1252, iso-8859-1 ou cp850.
+	OutputEncoding string `yaml:"output_encoding"`
+
+	timeout time.Duration
+	limits  resourceLimits
+	charset outputCharset
+}
+
+type manifestResources struct {
+	Memory    string  `yaml:"memory"`
+	CPU       float64 `yaml:"cpu"`
+	Processes int64   `yaml:"processes"`
+	OpenFiles int64   `yaml:"open_files"`
+}
+
+func defaultManifest() *botManifest {
+	return &botManifest{Entrypoint: "main.py", charset: charsetAuto}
+}
+
+// loadManifest lê bot.yaml de sourceDir. Problemas de schema são devolvidos
+// todos de uma vez em problems, para serem mostrados como linhas de ERROR.
+func loadManifest(sourceDir string, job *Job) (m *botManifest, found bool, problems []string) {
+	m = defaultManifest()
+	data, err := os.ReadFile(filepath.Join(sourceDir, manifestFile))
+	if os.IsNotExist(err) {
+		return m, false, nil
+	}
+	if err != nil {
+		return m, true, []string{fmt.Sprintf("erro ao ler %s: %v", manifestFile, err)}
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	// io.EOF indica um arquivo vazio, que vale como manifesto padrão.
+	if err := decoder.Decode(m); err != nil && !errors.Is(err, io.EOF) {
+		// Com erros de tipo o decoder ainda preenche os demais campos, então
+		// vale validar o resto e mostrar tudo de uma vez.
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return m, true, []string{err.Error()}
+		}
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, unknownFieldPattern.ReplaceAllString(msg, "campo desconhecido $1"))
+		}
+	}
+	if m.Entrypoint == "" {
+		m.Entrypoint = "main.py"
+	}
+	return m, true, append(problems, m.validate(sourceDir, job)...)
+}
+
+func (m *botManifest) validate(sourceDir string, job *Job) []string {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	workdir, ok := repoPath(sourceDir, m.Workdir)
+	if !ok {
+		add("workdir: %q precisa ser um caminho relativo dentro do repositório", m.Workdir)
+	} else if info, err := os.Stat(workdir); err != nil || !info.IsDir() {
+		add("workdir: diretório %q não encontrado", m.Workdir)
+	} else if entrypoint, ok := repoPath(workdir, m.Entrypoint); !ok {
+		add("entrypoint: %q precisa ser um caminho relativo dentro do repositório", m.Entrypoint)
+	} else if _, err := os.Stat(entrypoint); err != nil {
+		add("entrypoint: arquivo %q não encontrado em %q", m.Entrypoint, m.workdirLabel())
+	}
+
+	if m.Python != "" && !pythonVersionPattern.MatchString(m.Python) {
+		add("python: versão inválida %q (use por exemplo \"3.11\")", m.Python)
+	}
+
+	if m.Dependencies != "" {
+		if depFile, ok := repoPath(sourceDir, m.Dependencies); !ok {
+			add("dependencies: %q precisa ser um caminho relativo dentro do repositório", m.Dependencies)
+		} else if dependencyKind(depFile) == "" {
+			add("dependencies: %q não é suportado (use requirements.txt, pyproject.toml ou Pipfile)", m.Dependencies)
+		} else if _, err := os.Stat(depFile); err != nil {
+			add("dependencies: arquivo %q não encontrado", m.Dependencies)
+		}
+	}
+
+	if m.Timeout != "" {
+		timeout, err := time.ParseDuration(m.Timeout)
+		if err != nil || timeout <= 0 {
+			add("timeout: duração inválida %q (use por exemplo 90s, 30m ou 2h)", m.Timeout)
+		}
+		m.timeout = timeout
+	}
+
+	if m.Resources.Memory != "" {
+		memory, err := config.ParseByteSize(m.Resources.Memory)
+		if err != nil {
+			add("resources.memory: %v (use por exemplo 512M ou 2G)", err)
+		}
+		m.limits.MemoryBytes = memory
+	}
+	if m.Resources.CPU < 0 {
+		add("resources.cpu: deve ser positivo")
+	}
+	if m.Resources.Processes < 0 {
+		add("resources.processes: deve ser positivo")
+	}
+	if m.Resources.OpenFiles < 0 {
+		add("resources.open_files: deve ser positivo")
+	}
+	m.limits.CPUs = m.Resources.CPU
+	m.limits.Processes = m.Resources.Processes
+	m.limits.OpenFiles = m.Resources.OpenFiles
+
+	charset, err := parseOutputCharset(m.OutputEncoding)
+	if err != nil {
+		add("output_encoding: %v", err)
+	}
+	m.charset = charset
+
+	for _, name := range sortedKeys(m.Env) {
+		if !validation.IsEnvName(name) {
+			add("env: nome de variável inválido %q", name)
+		}
+	}
+
+	provided := make(map[string]bool)
+	for _, ref := range job.Bot.SecretRefs {
+		if envName, _, err := validation.ParseSecretRef(ref); err == nil {
+			provided[envName] = true
+		}
+	}
+	for _, name := range m.Secrets {
+		switch {
+		case !validation.IsEnvName(name):
+			add("secrets: nome de variável inválido %q", name)
+		case !provided[name]:
+			add("secrets: segredo obrigatório %s não foi informado em secret_refs", name)
+		}
+	}
+	return problems
+}
+
+func (m *botManifest) workdirLabel() string {
+	if m.Workdir == "" {
+		return "."
+	}
+	return m.Workdir
+}
+
+// repoPath junta rel a base, recusando caminhos absolutos ou que saiam de base.
+func repoPath(base, rel string) (string, bool) {
+	if filepath.IsAbs(rel) || strings.HasPrefix(rel, "/") || strings.HasPrefix(rel, `\`) {
+		return "", false
+	}
+	path := filepath.Join(base, rel)
+	if path != base && !strings.HasPrefix(path, base+string(filepath.Separator)) {
+		return "", false
+	}
+	return path, true
+}
+
+// dependencyKind classifica um arquivo de dependências pelo nome.
+func dependencyKind(path string) string {
+	name := filepath.Base(path)
+	switch {
+	case name == "pyproject.toml":
+		return "pyproject"
+	case name == "Pipfile":
+		return "pipfile"
+	case strings.HasSuffix(name, ".txt"):
+		return "requirements"
+	}
+	return ""
+}
+
+// envList devolve os valores padrão do manifesto em ordem estável.
+func (m *botManifest) envList() []string {
+	var env []string
+	for _, name := range sortedKeys(m.Env) {
+		env = append(env, name+"="+m.Env[name])
+	}
+	return env
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// loadJobManifest carrega o manifesto do job já clonado, reportando cada
+// problema de schema como uma linha de ERROR.
+func (s *OrchestratorService) loadJobManifest(job *Job, logStream *logSink) (*botManifest, error) {
+	sourceDir, _ := filepath.Abs(filepath.Join(s.versionDir(&job.Bot), "source"))
+	manifest, found, problems := loadManifest(sourceDir, job)
+	if !found {
+		return manifest, nil
+	}
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_ERROR,
+				fmt.Sprintf("%s: %s", manifestFile, problem)))
+		}
+		return nil, fmt.Errorf("%s inválido (%d problema(s))", manifestFile, len(problems))
+	}
+
+	summary := fmt.Sprintf("Manifesto %s: entrypoint %s", manifestFile, manifest.Entrypoint)
+	if manifest.Python != "" {
+		summary += ", python " + manifest.Python
+	}
+	if manifest.Dependencies != "" {
+		summary += ", dependências em " + manifest.Dependencies
+	}
+	if manifest.timeout > 0 {
+		summary += ", timeout " + manifest.timeout.String()
+	}
+	if !manifest.limits.empty() {
+		summary += ", limites: " + manifest.limits.String()
+	}
+	if manifest.charset != charsetAuto {
+		summary += ", saída em " + manifest.charset.name
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO, summary))
+	return manifest, nil
+}
@@ -0,0 +1,33 @@
+package orchestrator
+
+import (
+	"os/exec"
+	"time"
+)
+
+// killGracePeriod é o tempo entre o SIGTERM enviado ao grupo de processos do
+// bot e o SIGKILL definitivo. É variável só para os testes.
+var killGracePeriod = 10 * time.Second
+
+// bindProcessTree faz com que o cancelamento do contexto de cmd encerre o
+// processo e todos os seus filhos: primeiro um término gracioso e, depois de
+// killGracePeriod, um kill forçado do grupo inteiro.
+//
+// O kill forçado acontece mesmo que o líder já tenha terminado: filhos que
+// ignoram o SIGTERM continuam no grupo, e o PGID não é reaproveitado enquanto
+// houver algum membro vivo. Se não sobrou nenhum, o sinal só falha.
+func bindProcessTree(cmd *exec.Cmd) {
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		if err := signalProcessTree(cmd.Process, false); err != nil {
+			return signalProcessTree(cmd.Process, true)
+		}
+		time.AfterFunc(killGracePeriod, func() {
+			signalProcessTree(cmd.Process, true)
+		})
+		return nil
+	}
+	// Netos que herdaram stdout/stderr podem manter os pipes abertos mesmo
+	// depois do kill; WaitDelay garante que Wait retorne.
+	cmd.WaitDelay = killGracePeriod + 5*time.Second
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+package orchestrator
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// alive informa se o processo pid existe e não é um zumbi.
+func alive(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return false
+	}
+	// pid (comm) estado ...
+	fields := strings.Fields(string(data[strings.LastIndexByte(string(data), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+// Um filho que ignora o SIGTERM e não segura os pipes do bot precisa morrer
+// com o SIGKILL do grupo, mesmo depois de o líder ter terminado.
+func TestBindProcessTreeKillsSurvivors(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("/proc indisponível")
+	}
+	saved := killGracePeriod
+	killGracePeriod = 200 * time.Millisecond
+	defer func() { killGracePeriod = saved }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// O filho só informa o PID depois de ignorar o SIGTERM.
+	cmd := exec.CommandContext(ctx, "sh", "-c",
+		`sh -c 'trap "" TERM; echo $$; exec sleep 30 </dev/null >/dev/null 2>&1' & exec sleep 30`)
+	bindProcessTree(cmd)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatal(err)
+	}
+	child, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if alive(child) {
+			exec.Command("kill", "-9", strconv.Itoa(child)).Run()
+		}
+	}()
+
+	cancel()
+	if err := cmd.Wait(); err == nil {
+		t.Fatal("Wait não devolveu erro para o processo cancelado")
+	}
+	if !alive(child) {
+		t.Fatal("o filho deveria ter ignorado o SIGTERM")
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for alive(child) {
+		if time.Now().After(deadline) {
+			t.Fatalf("o filho %d continuou vivo depois do SIGKILL do grupo", child)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build windows
+
+package orchestrator
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// signalProcessTree usa o taskkill para encerrar p e toda a árvore de filhos.
+func signalProcessTree(p *os.Process, force bool) error {
+	args := []string{"/T", "/PID", strconv.Itoa(p.Pid)}
+	if force {
+		args = append([]string{"/F"}, args...)
+	}
+	return exec.Command("taskkill", args...).Run()
+}
+
+// exitSignal não se aplica no Windows: processos não terminam por sinais.
+func exitSignal(err error) string { return "" }
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// defaultPythonCandidates lista, em ordem de preferência, os interpretadores
+// procurados no PATH quando nenhum interpretador base foi configurado.
+func defaultPythonCandidates() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"python", "py", "python3"}
+	}
+	return []string{"python3", "python"}
+}
+
+// resolvePython devolve o caminho absoluto do interpretador usado para criar
+// o venv. Quando configured não está vazio (ex: "python3.11" ou um caminho do
+// pyenv), ele é o único candidato aceito.
+func resolvePython(configured string) (string, error) {
+	candidates := defaultPythonCandidates()
+	if configured != "" {
+		candidates = []string{configured}
+	}
+
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate)
+		if err == nil {
+			return filepath.Abs(path)
+		}
+	}
+
+	if configured != "" {
+		return "", fmt.Errorf("interpretador Python configurado não encontrado: %s", configured)
+	}
+	return "", fmt.Errorf("nenhum interpretador Python encontrado no PATH (procurado: %v)", candidates)
+}
+
+// pythonVersion devolve a versão (major.minor.micro) de um interpretador.
+func pythonVersion(python string) (string, error) {
+	out, err := exec.Command(python, "-c", "import sys; print('.'.join(map(str, sys.version_info[:3])))").Output()
+	if err != nil {
+		return "", fmt.Errorf("erro ao consultar a versão de %s: %v", python, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// versionMatches informa se actual (ex: "3.11.4") atende a want (ex: "3.11").
+func versionMatches(actual, want string) bool {
+	return actual == want || strings.HasPrefix(actual, want+".")
+}
+
+// resolvePythonVersion é como resolvePython, mas exige que o interpretador
+// seja da versão want (quando informada), procurando também por pythonX.Y
+// no PATH e, no Windows, pelo launcher py.
+func resolvePythonVersion(configured, want string) (string, error) {
+	if want == "" {
+		return resolvePython(configured)
+	}
+
+	var candidates []string
+	if configured != "" {
+		candidates = append(candidates, configured)
+	}
+	candidates = append(candidates, "python"+want)
+	candidates = append(candidates, defaultPythonCandidates()...)
+
+	var found []string
+	for _, candidate := range candidates {
+		path, err := exec.LookPath(candidate)
+		if err != nil {
+			continue
+		}
+		path, _ = filepath.Abs(path)
+		version, err := pythonVersion(path)
+		if err != nil {
+			continue
+		}
+		if versionMatches(version, want) {
+			return path, nil
+		}
+		found = append(found, fmt.Sprintf("%s (%s)", path, version))
+	}
+
+	if runtime.GOOS == "windows" {
+		out, err := exec.Command("py", "-"+want, "-c", "import sys; print(sys.executable)").Output()
+		if err == nil {
+			return strings.TrimSpace(string(out)), nil
+		}
+	}
+	return "", fmt.Errorf("nenhum interpretador Python %s encontrado (encontrados: %v)", want, found)
+}
+
+// venvVersion lê a versão do Python com que o venv foi criado, a partir do
+// pyvenv.cfg.
+func venvVersion(venvPath string) string {
+	data, err := os.ReadFile(filepath.Join(venvPath, "pyvenv.cfg"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if ok && (key == "version" || key == "version_info") {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// venvExecutable devolve o caminho de um executável (python, pip) dentro do
+// venv, respeitando o layout do sistema operacional: Scripts/*.exe no Windows
+// e bin/* nos sistemas POSIX.
+func venvExecutable(venvPath, name string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venvPath, "Scripts", name+".exe")
+	}
+	return filepath.Join(venvPath, "bin", name)
+}
+
+// botInterpreter escolhe o interpretador que executa o bot: o python do venv
+// quando o bot tem dependências, ou o interpretador base (na versão want, se
+// informada) quando não tem (venvPath vazio). Um venv sem interpretador ou de
+// outra versão é um erro: sem ele, o bot rodaria sem as dependências.
+func (s *OrchestratorService) botInterpreter(venvPath, want string) (string, error) {
+	if venvPath == "" {
+		return resolvePythonVersion(s.pythonBin, want)
+	}
+	venvPython := venvExecutable(venvPath, "python")
+	if _, err := os.Stat(venvPython); err != nil {
+		return "", fmt.Errorf("ambiente virtual %s sem interpretador: %v", venvPath, err)
+	}
+	if want != "" {
+		version := venvVersion(venvPath)
+		if version == "" {
+			return "", fmt.Errorf("ambiente virtual %s sem versão do Python no pyvenv.cfg; o manifesto pede %s", venvPath, want)
+		}
+		if !versionMatches(version, want) {
+			return "", fmt.Errorf("ambiente virtual %s tem Python %s, mas o manifesto pede %s", venvPath, version, want)
+		}
+	}
+	return venvPython, nil
+}
@@ -0,0 +1,84 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVersionMatches(t *testing.T) {
+	tests := []struct {
+		actual, want string
+		match        bool
+	}{
+		{"3.11.4", "3.11", true},
+		{"3.11.4", "3.11.4", true},
+		{"3.11.4", "3", true},
+		{"3.1.4", "3.11", false},
+		{"3.11.4", "3.1", false},
+		{"", "3.11", false},
+	}
+	for _, tt := range tests {
+		if got := versionMatches(tt.actual, tt.want); got != tt.match {
+			t.Errorf("versionMatches(%q, %q) = %v, esperado %v", tt.actual, tt.want, got, tt.match)
+		}
+	}
+}
+
+// makeVenv cria um venv falso com o pyvenv.cfg informado (nenhum, se cfg for
+// vazio).
+func makeVenv(t *testing.T, cfg string, withPython bool) string {
+	t.Helper()
+	venv := t.TempDir()
+	if cfg != "" {
+		if err := os.WriteFile(filepath.Join(venv, "pyvenv.cfg"), []byte(cfg), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if withPython {
+		python := venvExecutable(venv, "python")
+		if err := os.MkdirAll(filepath.Dir(python), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(python, nil, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return venv
+}
+
+func TestBotInterpreterVenv(t *testing.T) {
+	s := &OrchestratorService{}
+	cfg := "home = /usr/bin\nversion = 3.11.4\n"
+
+	tests := []struct {
+		name    string
+		venv    string
+		want    string
+		wantErr string // trecho do erro; vazio quando o python do venv é usado
+	}{
+		{"sem versão pedida", makeVenv(t, cfg, true), "", ""},
+		{"versão igual", makeVenv(t, cfg, true), "3.11", ""},
+		{"versão diferente", makeVenv(t, cfg, true), "3.12", "tem Python 3.11.4, mas o manifesto pede 3.12"},
+		{"sem pyvenv.cfg", makeVenv(t, "", true), "3.11", "sem versão do Python"},
+		{"sem interpretador", makeVenv(t, cfg, false), "", "sem interpretador"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.botInterpreter(tt.venv, tt.want)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("botInterpreter = (%q, %v), esperado erro com %q", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := venvExecutable(tt.venv, "python"); got != want {
+				t.Fatalf("botInterpreter = %q, esperado %q", got, want)
+			}
+		})
+	}
+}
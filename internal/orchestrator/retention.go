@@ -0,0 +1,309 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"orchestrator/internal/config"
+	"orchestrator/structs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Motivos de remoção informados em PruneBots.
+const (
+	pruneKeepVersions = "keep_versions"
+	pruneTTL          = "ttl"
+	pruneMaxSize      = "max_total_bytes"
+)
+
+var (
+	ErrVersionNotFound = errors.New("versão não encontrada")
+	ErrVersionInUse    = errors.New("versão em uso por um job")
+)
+
+// retentionPolicy define o que a limpeza remove. Zero desliga cada regra.
+type retentionPolicy struct {
+	// KeepVersions é quantas versões manter por bot, das usadas mais
+	// recentemente.
+	KeepVersions int
+	// MaxTotalBytes limita o tamanho somado do diretório dos bots e do cache
+	// de ambientes; o que foi usado há mais tempo sai primeiro.
+	MaxTotalBytes int64
+	// TTL é o tempo máximo desde a última execução.
+	TTL time.Duration
+}
+
+func (p retentionPolicy) empty() bool {
+	return p == retentionPolicy{}
+}
+
+// diskEntry é uma versão de bot ou um ambiente do cache de dependências.
+type diskEntry struct {
+	BotID    string
+	Version  string
+	EnvKey   string
+	Path     string
+	Size     int64
+	LastUsed time.Time
+	Reason   string
+}
+
+func (e diskEntry) String() string {
+	if e.EnvKey != "" {
+		return "ambiente " + e.EnvKey
+	}
+	return e.BotID + "/" + e.Version
+}
+
+// scanDisk lista as versões de bots e os ambientes do cache.
+func (s *OrchestratorService) scanDisk() ([]diskEntry, error) {
+	var entries []diskEntry
+	bots, err := os.ReadDir(s.botsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, bot := range bots {
+		// Diretórios com "." no início, como o dos logs, não são bots.
+		if !bot.IsDir() || strings.HasPrefix(bot.Name(), ".") {
+			continue
+		}
+		versions, err := os.ReadDir(filepath.Join(s.botsDir, bot.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			if !version.IsDir() {
+				continue
+			}
+			path := filepath.Join(s.botsDir, bot.Name(), version.Name())
+			entries = append(entries, diskEntry{
+				BotID:    bot.Name(),
+				Version:  version.Name(),
+				Path:     path,
+				Size:     dirSize(path),
+				LastUsed: versionLastUsed(path),
+			})
+		}
+	}
+
+	envs, err := os.ReadDir(s.venvCacheDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, env := range envs {
+		if !env.IsDir() {
+			continue
+		}
+		path := filepath.Join(s.venvCacheDir(), env.Name())
+		lastUsed := modTime(path)
+		if t := modTime(filepath.Join(path, envCacheFile)); t.After(lastUsed) {
+			lastUsed = t
+		}
+		entries = append(entries, diskEntry{
+			EnvKey:   env.Name(),
+			Path:     path,
+			Size:     dirSize(path),
+			LastUsed: lastUsed,
+		})
+	}
+	return entries, nil
+}
+
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// versionLastUsed estima a última execução de uma versão: o revision.json é
+// regravado a cada deploy.
+func versionLastUsed(path string) time.Time {
+	last := modTime(path)
+	if t := modTime(filepath.Join(path, revisionFile)); t.After(last) {
+		last = t
+	}
+	return last
+}
+
+// planPrune escolhe o que remover: as versões além de KeepVersions em cada
+// bot, o que passou do TTL e, por fim, o que foi usado há mais tempo até o
+// total caber em MaxTotalBytes.
+func planPrune(entries []diskEntry, policy retentionPolicy, now time.Time) []diskEntry {
+	sorted := append([]diskEntry(nil), entries...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].LastUsed.After(sorted[j].LastUsed) })
+
+	var total int64
+	perBot := make(map[string]int)
+	for i := range sorted {
+		e := &sorted[i]
+		total += e.Size
+		if e.EnvKey == "" {
+			perBot[e.BotID]++
+			if policy.KeepVersions > 0 && perBot[e.BotID] > policy.KeepVersions {
+				e.Reason = pruneKeepVersions
+				continue
+			}
+		}
+		if policy.TTL > 0 && now.Sub(e.LastUsed) > policy.TTL {
+			e.Reason = pruneTTL
+		}
+	}
+
+	var remove []diskEntry
+	for _, e := range sorted {
+		if e.Reason != "" {
+			remove = append(remove, e)
+			total -= e.Size
+		}
+	}
+	if policy.MaxTotalBytes > 0 {
+		for i := len(sorted) - 1; i >= 0 && total > policy.MaxTotalBytes; i-- {
+			if e := sorted[i]; e.Reason == "" {
+				e.Reason = pruneMaxSize
+				remove = append(remove, e)
+				total -= e.Size
+			}
+		}
+	}
+	return remove
+}
+
+// lockEntry obtém o lock da versão ou do ambiente, falhando se um job o
+// estiver usando. Um job não finalizado conta mesmo antes de obter o lock da
+// versão (ainda na fila) ou depois de liberá-lo (enviando o resumo).
+func (s *OrchestratorService) lockEntry(e diskEntry) (func(), bool) {
+	key := "venv:" + e.EnvKey
+	if e.EnvKey == "" {
+		key = e.BotID + "/" + e.Version
+	}
+	release, ok := s.tryLock(key)
+	if !ok {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		uses := job.Bot.BotID == e.BotID && job.Bot.Version == e.Version
+		if e.EnvKey != "" {
+			uses = job.venvKey == e.EnvKey
+		}
+		if uses && !isFinalState(job.State) {
+			release()
+			return nil, false
+		}
+	}
+	return release, true
+}
+
+func removeEntry(e diskEntry) error {
+	if err := os.RemoveAll(e.Path); err != nil {
+		return fmt.Errorf("erro ao remover %s: %v", e, err)
+	}
+	if e.EnvKey == "" {
+		// Remove a pasta do bot quando era a última versão.
+		os.Remove(filepath.Dir(e.Path))
+	}
+	return nil
+}
+
+// prune aplica policy e devolve o que foi removido (ou seria, com dryRun) e o
+// tamanho total resultante. O que estiver em uso por um job é mantido.
+func (s *OrchestratorService) prune(policy retentionPolicy, dryRun bool) ([]diskEntry, int64, error) {
+	entries, err := s.scanDisk()
+	if err != nil {
+		return nil, 0, fmt.Errorf("erro ao examinar o disco: %v", err)
+	}
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+
+	var removed []diskEntry
+	var errs []error
+	for _, e := range planPrune(entries, policy, time.Now()) {
+		release, ok := s.lockEntry(e)
+		if !ok {
+			continue
+		}
+		var err error
+		if !dryRun {
+			err = removeEntry(e)
+		}
+		release()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, e)
+		total -= e.Size
+	}
+	return removed, total, errors.Join(errs...)
+}
+
+// DeleteBotVersion remove o diretório de uma versão e devolve quantos bytes
+// foram liberados. Os logs dos jobs ficam em jobLogsDir e são mantidos.
+func (s *OrchestratorService) DeleteBotVersion(botID, version string) (int64, error) {
+	path := s.versionDir(&structs.Bot{BotID: botID, Version: version})
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return 0, ErrVersionNotFound
+	}
+	e := diskEntry{BotID: botID, Version: version, Path: path}
+	release, ok := s.lockEntry(e)
+	if !ok {
+		return 0, ErrVersionInUse
+	}
+	defer release()
+	e.Size = dirSize(path)
+	if err := removeEntry(e); err != nil {
+		return 0, err
+	}
+	return e.Size, nil
+}
+
+// runJanitor aplica a política de retenção do agente ao iniciar e depois a
+// cada interval.
+func (s *OrchestratorService) runJanitor(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || s.retention.empty() {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		removed, total, err := s.prune(s.retention, false)
+		if err != nil {
+			fmt.Printf("janitor: %v\n", err)
+		}
+		for _, e := range removed {
+			fmt.Printf("janitor: %s removido (%s, %s)\n", e, config.FormatBytes(e.Size), e.Reason)
+		}
+		if len(removed) > 0 {
+			fmt.Printf("janitor: %d item(ns) removido(s); ocupando %s\n", len(removed), config.FormatBytes(total))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"orchestrator/internal/validation"
+	"orchestrator/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (h *Handler) DeleteBotVersion(ctx context.Context, req *pb.DeleteBotVersionRequest) (*pb.DeleteBotVersionResponse, error) {
+	if err := validation.BotVersion(req.BotId, req.Version); err != nil {
+		return nil, err
+	}
+	freed, err := h.service.DeleteBotVersion(req.BotId, req.Version)
+	switch {
+	case errors.Is(err, ErrVersionNotFound):
+		return nil, status.Errorf(codes.NotFound, "versão %s/%s não encontrada", req.BotId, req.Version)
+	case errors.Is(err, ErrVersionInUse):
+		return nil, status.Errorf(codes.FailedPrecondition, "versão %s/%s em uso por um job", req.BotId, req.Version)
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.DeleteBotVersionResponse{FreedBytes: freed}, nil
+}
+
+// PruneBots aplica a política de retenção do agente; os campos preenchidos
+// na requisição substituem as regras correspondentes.
+func (h *Handler) PruneBots(ctx context.Context, req *pb.PruneBotsRequest) (*pb.PruneBotsResponse, error) {
+	policy := h.service.retention
+	if req.KeepVersions > 0 {
+		policy.KeepVersions = int(req.KeepVersions)
+	}
+	if req.MaxTotalBytes > 0 {
+		policy.MaxTotalBytes = req.MaxTotalBytes
+	}
+	if req.Ttl != nil {
+		if err := req.Ttl.CheckValid(); err != nil || req.Ttl.AsDuration() < 0 {
+			return nil, status.Error(codes.InvalidArgument, "ttl inválido")
+		}
+		policy.TTL = req.Ttl.AsDuration()
+	}
+	if policy.empty() {
+		return nil, status.Error(codes.FailedPrecondition, "nenhuma regra de retenção configurada no agente ou informada na requisição")
+	}
+
+	removed, total, err := h.service.prune(policy, req.DryRun)
+	resp := &pb.PruneBotsResponse{TotalBytes: total, DryRun: req.DryRun}
+	for _, e := range removed {
+		resp.Items = append(resp.Items, &pb.PrunedItem{
+			BotId:      e.BotID,
+			Version:    e.Version,
+			EnvKey:     e.EnvKey,
+			SizeBytes:  e.Size,
+			LastUsedAt: timestamppb.New(e.LastUsed),
+			Reason:     e.Reason,
+		})
+		resp.FreedBytes += e.Size
+	}
+	if err != nil {
+		if len(removed) == 0 {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		// A limpeza parcial é devolvida; o restante fica para a próxima.
+		fmt.Printf("PruneBots: %v\n", err)
+	}
+	return resp, nil
+}
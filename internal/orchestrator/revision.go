@@ -0,0 +1,98 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"orchestrator/pb"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// revisionFile guarda, no diretório da versão, o commit implantado.
+const revisionFile = "revision.json"
+
+var commitSHAPattern = regexp.MustCompile(`^([0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// revision descreve o que foi (ou será) implantado para uma versão. Ref fica
+// vazio quando a versão já é um SHA.
+type revision struct {
+	Version    string    `json:"version"`
+	Ref        string    `json:"ref,omitempty"`
+	Commit     string    `json:"commit"`
+	ResolvedAt time.Time `json:"resolved_at"`
+
+	// pinned indica que o checkout precisa terminar exatamente em Commit.
+	pinned bool
+}
+
+func isCommitSHA(version string) bool {
+	return commitSHAPattern.MatchString(version)
+}
+
+func isTagRef(ref string) bool {
+	return strings.HasPrefix(ref, "refs/tags/")
+}
+
+// fetchRef é o que é pedido ao repositório remoto no git fetch.
+func (r revision) fetchRef() string {
+	if r.Ref == "" {
+		return r.Commit
+	}
+	return r.Ref
+}
+
+func readRevision(basePath string) (revision, bool) {
+	data, err := os.ReadFile(filepath.Join(basePath, revisionFile))
+	if err != nil {
+		return revision{}, false
+	}
+	var rev revision
+	if err := json.Unmarshal(data, &rev); err != nil || rev.Commit == "" {
+		return revision{}, false
+	}
+	return rev, true
+}
+
+func writeRevision(basePath string, rev revision) error {
+	data, err := json.MarshalIndent(rev, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(basePath, revisionFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erro ao registrar o commit implantado: %v", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// resolveRevision decide qual commit implantar. SHAs são usados como estão;
+// uma tag já registrada em revision.json continua no commit registrado, para
+// que reexecuções sejam reprodutíveis mesmo se a tag for movida; branches são
+// sempre consultadas no repositório.
+func resolveRevision(ctx context.Context, remote gitRemote, version, basePath string, logStream *logSink) (revision, error) {
+	if isCommitSHA(version) {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+			fmt.Sprintf("Versão %s é um commit; implantando exatamente esse SHA.", version)))
+		return revision{Version: version, Commit: version, pinned: true}, nil
+	}
+
+	if saved, ok := readRevision(basePath); ok && saved.Version == version && isTagRef(saved.Ref) {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+			fmt.Sprintf("Tag %s fixada no commit %s (registrado em %s).", version, saved.Commit, saved.ResolvedAt.Format(time.RFC3339))))
+		saved.pinned = true
+		return saved, nil
+	}
+
+	ref, sha, err := resolveRemoteVersion(ctx, remote, version)
+	if err != nil {
+		return revision{}, err
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+		fmt.Sprintf("Versão %s (%s) resolvida para o commit %s", version, ref, sha)))
+	return revision{Version: version, Ref: ref, Commit: sha}, nil
+}
@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsCommitSHA(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{strings.Repeat("a", 40), true},
+		{"0123456789abcdef0123456789abcdef01234567", true},
+		{strings.Repeat("f", 64), true},
+		{strings.Repeat("a", 39), false},
+		{strings.Repeat("a", 41), false},
+		{"0123456789ABCDEF0123456789ABCDEF01234567", false},
+		{"abc1234", false},
+		{"main", false},
+		{"v1.2.3", false},
+	}
+	for _, tt := range tests {
+		if got := isCommitSHA(tt.version); got != tt.want {
+			t.Errorf("isCommitSHA(%q) = %v, esperado %v", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestMatchRemoteRef(t *testing.T) {
+	const (
+		shaBranch = "1111111111111111111111111111111111111111"
+		shaTagObj = "2222222222222222222222222222222222222222"
+		shaTagCmt = "3333333333333333333333333333333333333333"
+		shaLight  = "4444444444444444444444444444444444444444"
+	)
+	lsRemote := strings.Join([]string{
+		shaBranch + "\trefs/heads/main",
+		shaBranch + "\trefs/heads/v1",
+		shaTagObj + "\trefs/tags/v1",
+		shaTagCmt + "\trefs/tags/v1^{}",
+		shaTagObj + "\trefs/tags/v2",
+		shaTagCmt + "\trefs/tags/v2^{}",
+		shaLight + "\trefs/tags/v3",
+		"",
+	}, "\n")
+
+	tests := []struct {
+		name    string
+		version string
+		wantRef string
+		wantSHA string
+		wantOK  bool
+	}{
+		{"branch", "main", "refs/heads/main", shaBranch, true},
+		{"branch tem precedência sobre tag", "v1", "refs/heads/v1", shaBranch, true},
+		{"tag anotada usa o commit", "v2", "refs/tags/v2", shaTagCmt, true},
+		{"tag leve", "v3", "refs/tags/v3", shaLight, true},
+		{"inexistente", "v4", "", "", false},
+		{"prefixo não casa", "mai", "", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, sha, ok := matchRemoteRef(lsRemote, tt.version)
+			if ref != tt.wantRef || sha != tt.wantSHA || ok != tt.wantOK {
+				t.Fatalf("matchRemoteRef(%q) = (%q, %q, %v), esperado (%q, %q, %v)",
+					tt.version, ref, sha, ok, tt.wantRef, tt.wantSHA, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRevisionFile(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := readRevision(dir); ok {
+		t.Fatal("readRevision sem arquivo devolveu uma revisão")
+	}
+
+	want := revision{
+		Version:    "v1",
+		Ref:        "refs/tags/v1",
+		Commit:     strings.Repeat("a", 40),
+		ResolvedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		pinned:     true,
+	}
+	if err := writeRevision(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := readRevision(dir)
+	if !ok {
+		t.Fatal("readRevision não leu a revisão gravada")
+	}
+	want.pinned = false // não é persistido
+	if got != want {
+		t.Fatalf("readRevision = %+v, esperado %+v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(dir, revisionFile+".tmp")); !os.IsNotExist(err) {
+		t.Fatalf("arquivo temporário ficou para trás: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, revisionFile), []byte(`{"version":"v1"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readRevision(dir); ok {
+		t.Fatal("readRevision aceitou uma revisão sem commit")
+	}
+}
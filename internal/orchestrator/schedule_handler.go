@@ -0,0 +1,102 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"orchestrator/internal/auth"
+	"orchestrator/internal/scheduler"
+	"orchestrator/pb"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func misfireFromProto(policy pb.MisfirePolicy) scheduler.MisfirePolicy {
+	if policy == pb.MisfirePolicy_MISFIRE_POLICY_RUN_ONCE {
+		return scheduler.MisfireRunOnce
+	}
+	return scheduler.MisfireSkip
+}
+
+func misfireToProto(policy scheduler.MisfirePolicy) pb.MisfirePolicy {
+	if policy == scheduler.MisfireRunOnce {
+		return pb.MisfirePolicy_MISFIRE_POLICY_RUN_ONCE
+	}
+	return pb.MisfirePolicy_MISFIRE_POLICY_SKIP
+}
+
+func optionalTimestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func scheduleToProto(sched scheduler.Schedule) *pb.Schedule {
+	return &pb.Schedule{
+		ScheduleId:    sched.ID,
+		Cron:          sched.Cron,
+		Timezone:      sched.Timezone,
+		Bot:           botToRequest(&sched.Bot),
+		MisfirePolicy: misfireToProto(sched.Misfire),
+		Paused:        sched.Paused,
+		CreatedAt:     timestamppb.New(sched.CreatedAt),
+		NextRunAt:     optionalTimestamp(sched.NextRunAt),
+		LastRunAt:     optionalTimestamp(sched.LastRunAt),
+		LastJobId:     sched.LastJobID,
+		LastError:     sched.LastError,
+		CreatedBy:     sched.CreatedBy,
+	}
+}
+
+func scheduleError(err error, id string) error {
+	switch {
+	case errors.Is(err, scheduler.ErrScheduleNotFound):
+		return status.Errorf(codes.NotFound, "agendamento %s não encontrado", id)
+	case errors.Is(err, scheduler.ErrInvalidSchedule):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func (h *Handler) CreateSchedule(ctx context.Context, req *pb.CreateScheduleRequest) (*pb.Schedule, error) {
+	if req.Bot == nil {
+		return nil, status.Error(codes.InvalidArgument, "bot é obrigatório")
+	}
+	if err := h.policy.DeployRequest(req.Bot, "bot."); err != nil {
+		return nil, err
+	}
+	sched, err := h.scheduler.Create(req.Cron, req.Timezone, *botFromRequest(req.Bot), misfireFromProto(req.MisfirePolicy), auth.Subject(ctx))
+	if err != nil {
+		return nil, scheduleError(err, "")
+	}
+	return scheduleToProto(sched), nil
+}
+
+func (h *Handler) ListSchedules(ctx context.Context, req *pb.ListSchedulesRequest) (*pb.ListSchedulesResponse, error) {
+	resp := &pb.ListSchedulesResponse{}
+	for _, sched := range h.scheduler.List() {
+		if !auth.CanAccessBot(ctx, sched.Bot.BotID) {
+			continue
+		}
+		resp.Schedules = append(resp.Schedules, scheduleToProto(sched))
+	}
+	return resp, nil
+}
+
+func (h *Handler) DeleteSchedule(ctx context.Context, req *pb.DeleteScheduleRequest) (*pb.DeleteScheduleResponse, error) {
+	if err := h.scheduler.Delete(req.ScheduleId); err != nil {
+		return nil, scheduleError(err, req.ScheduleId)
+	}
+	return &pb.DeleteScheduleResponse{}, nil
+}
+
+func (h *Handler) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.Schedule, error) {
+	sched, err := h.scheduler.SetPaused(req.ScheduleId, req.Paused)
+	if err != nil {
+		return nil, scheduleError(err, req.ScheduleId)
+	}
+	return scheduleToProto(sched), nil
+}
@@ -1,10 +1,12 @@
 package orchestrator
 
 import (
-	"bufio"
 	"context"
+	"errors"
 	"fmt"
-	"io"
+	"orchestrator/internal/config"
+	"orchestrator/internal/credentials"
+	"orchestrator/internal/secrets"
 	"orchestrator/pb"
 	"orchestrator/structs"
 	"os"
@@ -14,11 +16,35 @@ import (
 	"sync"
 	"time"
 	"unicode/utf8"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type OrchestratorService struct {
 	bases_path map[string]string
 	mu         sync.Mutex
+	pythonBin  string
+	botsDir    string
+	cacheDir   string
+
+	credentials *credentials.Store
+	secrets     *secrets.Store
+
+	cloneTimeout      time.Duration
+	defaultBotTimeout time.Duration
+	installTimeout    time.Duration
+	logBufferLines    int
+	lines             lineOptions
+	limits            resourceLimits
+	cgroupParent      string
+	retention         retentionPolicy
+	jobs              map[string]*Job
+	jobOrder          []string
+	jobHistory        int
+	jobHistoryTTL     time.Duration
+
+	versionLocks map[string]*keyedLock
+	runSlots     chan struct{}
 }
 
 func sanitizeUTF8(s string) string {
@@ -36,178 +62,429 @@ func sanitizeUTF8(s string) string {
 	return builder.String()
 }
 
-func NewOrchestratorService() *OrchestratorService {
-	return &OrchestratorService{bases_path: make(map[string]string)}
+// newLog cria uma mensagem gerada pelo próprio agente, com o horário do
+// servidor no momento em que foi produzida.
+func newLog(phase pb.DeployPhase, status pb.LogStatus, line string) *pb.LogResponse {
+	return &pb.LogResponse{
+		Line:      line,
+		Status:    status,
+		Source:    pb.LogSource_LOG_SOURCE_AGENT,
+		Phase:     phase,
+		Timestamp: timestamppb.Now(),
+	}
 }
 
-func (s *OrchestratorService) ExecuteDeployment(deployRequest *structs.Bot, logStream chan<- *pb.LogResponse) error {
-	basePath := fmt.Sprintf("./bots/%s/%s", deployRequest.BotID, deployRequest.Version)
-	sourceDir := filepath.Join(basePath, "source")
+// newOutputLog cria uma mensagem com uma linha lida do stdout/stderr de um
+// processo (git, pip ou o bot).
+func newOutputLog(phase pb.DeployPhase, source pb.LogSource, line string) *pb.LogResponse {
+	return &pb.LogResponse{
+		Line:      line,
+		Status:    pb.LogStatus_LOG_STATUS_INFO,
+		Source:    source,
+		Phase:     phase,
+		Timestamp: timestamppb.Now(),
+	}
+}
 
-	if _, err := os.Stat(sourceDir); err == nil {
-		logStream <- &pb.LogResponse{Line: "Versão já existe localmente. Pulando clone.", Status: "INFO"}
-		return nil
+func NewOrchestratorService(cfg config.Server) *OrchestratorService {
+	return &OrchestratorService{
+		bases_path: make(map[string]string),
+		pythonBin:  cfg.PythonInterpreter,
+		botsDir:    cfg.BotsDir,
+		cacheDir:   cfg.CacheDir,
+
+		credentials: credentials.NewStore(cfg.CredentialsFile),
+		secrets:     secrets.NewStore(cfg.SecretsFile, cfg.SecretsKeyFile),
+
+		cloneTimeout:      cfg.CloneTimeout,
+		defaultBotTimeout: cfg.BotTimeout,
+		installTimeout:    cfg.InstallTimeout,
+		logBufferLines:    cfg.LogBufferLines,
+		lines: lineOptions{
+			maxBytes:  int(cfg.LogMaxLineBytes),
+			idleFlush: cfg.LogPartialFlush,
+		},
+		limits: resourceLimits{
+			MemoryBytes: int64(cfg.Limits.Memory),
+			CPUs:        cfg.Limits.CPU,
+			Processes:   cfg.Limits.Processes,
+			OpenFiles:   cfg.Limits.OpenFiles,
+		},
+		cgroupParent: cfg.Limits.CgroupParent,
+		retention: retentionPolicy{
+			KeepVersions:  cfg.Retention.KeepVersions,
+			MaxTotalBytes: int64(cfg.Retention.MaxSize),
+			TTL:           cfg.Retention.TTL,
+		},
+		jobs:          make(map[string]*Job),
+		jobHistory:    cfg.JobHistory,
+		jobHistoryTTL: cfg.JobHistoryTTL,
+
+		versionLocks: make(map[string]*keyedLock),
+		runSlots:     make(chan struct{}, cfg.MaxConcurrentRuns),
 	}
+}
 
-	if err := os.MkdirAll(basePath, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório base: %v", err)
+// versionDir é o diretório de uma versão de bot: clone, revision.json e logs.
+func (s *OrchestratorService) versionDir(bot *structs.Bot) string {
+	return filepath.Join(s.botsDir, bot.BotID, bot.Version)
+}
+
+// RunJob executa o ciclo completo de um job (clone, dependências e bot) e
+// registra o estado final no job.
+func (s *OrchestratorService) RunJob(job *Job, logStream *logSink) error {
+	defer job.cancel()
+
+	release, err := s.acquireRun(job, logStream)
+	if err != nil {
+		return s.failJob(job, pb.FailureReason_FAILURE_REASON_CANCELLED, -1, err, logStream)
 	}
+	defer release()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
+	// Os segredos são lidos antes do clone para que um segredo ausente falhe
+	// o job logo no início.
+	env, err := s.botEnv(job)
+	if err != nil {
+		return s.failJob(job, pb.FailureReason_FAILURE_REASON_INVALID_CONFIG, -1, err, logStream)
+	}
+	if err := s.ExecuteDeployment(job, logStream); err != nil {
+		reason := pb.FailureReason_FAILURE_REASON_CLONE
+		if errors.Is(err, credentials.ErrCredentialNotFound) || errors.Is(err, credentials.ErrCredentialNotAllowed) {
+			reason = pb.FailureReason_FAILURE_REASON_INVALID_CONFIG
+		}
+		return s.failJob(job, reason, -1, err, logStream)
+	}
+	manifest, err := s.loadJobManifest(job, logStream)
+	if err != nil {
+		return s.failJob(job, pb.FailureReason_FAILURE_REASON_INVALID_CONFIG, -1, err, logStream)
+	}
+	if err := s.RunBot(job, manifest, env, logStream); err != nil {
+		reason := pb.FailureReason_FAILURE_REASON_BOT_EXIT
+		if phaseForState(job) == pb.DeployPhase_DEPLOY_PHASE_INSTALL {
+			reason = pb.FailureReason_FAILURE_REASON_DEPENDENCIES
+		}
+		return s.failJob(job, reason, exitCodeFromError(err), err, logStream)
+	}
+	s.finishJob(job, pb.JobState_JOB_STATE_SUCCEEDED, pb.FailureReason_FAILURE_REASON_UNSPECIFIED, 0, nil)
+	return nil
+}
 
-	logStream <- &pb.LogResponse{
-		Line:   fmt.Sprintf("Executando: git clone -b %s %s %s", deployRequest.Version, deployRequest.GitRepo, sourceDir),
-		Status: "INFO",
+// failJob registra a falha do job, distinguindo um cancelamento pedido via
+// CancelJob de um erro real.
+func (s *OrchestratorService) failJob(job *Job, reason pb.FailureReason, exitCode int, err error, logStream *logSink) error {
+	if job.ctx.Err() != nil {
+		s.finishJob(job, pb.JobState_JOB_STATE_CANCELLED, pb.FailureReason_FAILURE_REASON_CANCELLED, exitCode, context.Canceled)
+		logStream.send(newLog(phaseForState(job), pb.LogStatus_LOG_STATUS_CANCELLED, "Execução cancelada."))
+		return context.Canceled
 	}
+	s.finishJob(job, pb.JobState_JOB_STATE_FAILED, reason, exitCode, err)
+	return err
+}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", "-b", deployRequest.Version, deployRequest.GitRepo, sourceDir)
+func (s *OrchestratorService) ExecuteDeployment(job *Job, logStream *logSink) error {
+	deployRequest := &job.Bot
+	s.setJobState(job, pb.JobState_JOB_STATE_CLONING)
 
-	stdout, _ := cmd.StdoutPipe()
-	stderr, _ := cmd.StderrPipe()
+	basePath := s.versionDir(deployRequest)
+	sourceDir := filepath.Join(basePath, "source")
 
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("falha ao iniciar git clone: %v", err)
+	if err := os.MkdirAll(basePath, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório base: %v", err)
 	}
 
-	var wg sync.WaitGroup
-	var stderrLines []string
-	var stderrMu sync.Mutex
+	ctx, cancel := context.WithTimeout(job.ctx, s.cloneTimeout)
+	defer cancel()
 
-	sendStdout := func(r io.Reader) {
-		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			logStream <- &pb.LogResponse{
-				Line:   sanitizeUTF8(scanner.Text()),
-				Status: "INFO",
-			}
+	if deployRequest.ForceReclone {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO, "force_reclone: descartando o clone e o commit registrados."))
+		if err := os.RemoveAll(sourceDir); err != nil {
+			return fmt.Errorf("erro ao remover o clone existente: %v", err)
 		}
+		os.Remove(filepath.Join(basePath, revisionFile))
 	}
 
-	sendStderr := func(r io.Reader) {
-		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			line := sanitizeUTF8(scanner.Text())
-			stderrMu.Lock()
-			stderrLines = append(stderrLines, line)
-			stderrMu.Unlock()
-			logStream <- &pb.LogResponse{
-				Line:   line,
-				Status: "INFO",
-			}
-		}
+	remote, err := s.gitRemoteFor(job, logStream)
+	if err != nil {
+		return err
 	}
+	defer remote.auth.close()
 
-	wg.Add(2)
-	go sendStdout(stdout)
-	go sendStderr(stderr)
+	rev, err := resolveRevision(ctx, remote, deployRequest.Version, basePath, logStream)
+	if err != nil {
+		return fmt.Errorf("erro ao resolver a versão: %v", err)
+	}
 
-	cmdErr := cmd.Wait()
-	wg.Wait() // Aguarda todas as goroutines terminarem de ler
+	if err := syncSource(ctx, sourceDir, remote, rev, logStream); err != nil {
+		return err
+	}
 
-	if cmdErr != nil {
-		errMsg := strings.Join(stderrLines, "; ")
-		return fmt.Errorf("erro durante o git clone: %v - stderr: %s", cmdErr, errMsg)
+	headSHA, err := gitOutput(ctx, sourceDir, nil, "rev-parse", "HEAD")
+	if err != nil {
+		return fmt.Errorf("erro ao ler o commit do clone: %v", err)
+	}
+	if headSHA != rev.Commit {
+		if rev.pinned {
+			return fmt.Errorf("o repositório entregou o commit %s, mas a versão %s está fixada em %s (use force_reclone para resolver de novo)",
+				headSHA, deployRequest.Version, rev.Commit)
+		}
+		// A branch pode ter andado entre o ls-remote e o fetch; vale o que foi
+		// de fato baixado.
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_CLONE, pb.LogStatus_LOG_STATUS_INFO,
+			fmt.Sprintf("A versão mudou durante o deploy; usando o commit %s", headSHA)))
+		rev.Commit = headSHA
 	}
 
-	logStream <- &pb.LogResponse{Line: "Clone finalizado com sucesso!", Status: "SUCCESS"}
+	if rev.ResolvedAt.IsZero() {
+		rev.ResolvedAt = time.Now().UTC()
+	}
+	if err := writeRevision(basePath, rev); err != nil {
+		return err
+	}
+	s.setResolvedCommit(job, headSHA)
 	return nil
 }
 
-func (s *OrchestratorService) RunBot(bot *structs.Bot, logStream chan<- *pb.LogResponse) error {
+func (s *OrchestratorService) RunBot(job *Job, manifest *botManifest, env []string, logStream *logSink) error {
+	bot := &job.Bot
 
-	if err := s.installRequirements(bot, logStream); err != nil {
-		logStream <- &pb.LogResponse{Line: fmt.Sprintf("Erro ao instalar dependências: %v", err), Status: "ERROR"}
+	s.setJobState(job, pb.JobState_JOB_STATE_INSTALLING)
+	venvPath, err := s.installRequirements(job, manifest, logStream)
+	if err != nil {
+		if job.ctx.Err() == nil {
+			logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Erro ao instalar dependências: %v", err)))
+		}
+		return err
+	}
+	sourceDir, _ := filepath.Abs(filepath.Join(s.versionDir(bot), "source"))
+	pythonPath, err := s.botInterpreter(venvPath, manifest.Python)
+	if err != nil {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Erro ao localizar o interpretador Python: %v", err)))
 		return err
 	}
-	botPath, _ := filepath.Abs(fmt.Sprintf("./bots/%s/%s/source", bot.BotID, bot.Version))
-	venvPath, _ := filepath.Abs(fmt.Sprintf("./bots/%s/%s/venv", bot.BotID, bot.Version))
-	pythonPath := filepath.Join(venvPath, "Scripts", "python.exe")
 
-	cmd := exec.Command(pythonPath, "main.py")
-	cmd.Dir = botPath
-	stdout, _ := cmd.StdoutPipe()
-	stderr, _ := cmd.StderrPipe()
-	if err := cmd.Start(); err != nil {
-		logStream <- &pb.LogResponse{Line: fmt.Sprintf("Falha ao iniciar o bot: %v", err), Status: "ERROR"}
+	ctx := job.ctx
+	timeout := s.botTimeout(job, manifest)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(job.ctx, timeout)
+		defer cancel()
+	}
+
+	s.setJobState(job, pb.JobState_JOB_STATE_RUNNING)
+	cmd := exec.CommandContext(ctx, pythonPath, append([]string{manifest.Entrypoint}, manifest.Args...)...)
+	bindProcessTree(cmd)
+	cmd.Dir = filepath.Join(sourceDir, manifest.Workdir)
+	// Em entradas repetidas vale a última: o env do DeployRequest e os
+	// segredos sobrepõem os padrões do manifesto.
+	cmd.Env = append(append(os.Environ(), manifest.envList()...), env...)
+
+	limits := s.limits.restrict(manifest.limits)
+	enforcer, err := prepareLimits(cmd, job.ID, limits, s.cgroupParent)
+	if err != nil {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Erro ao aplicar limites de recursos: %v", err)))
 		return err
 	}
-	var wg sync.WaitGroup
-	senLogs := func(r io.Reader) {
-		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			logStream <- &pb.LogResponse{
-				Line:   sanitizeUTF8(scanner.Text()),
-				Status: "INFO",
+	defer enforcer.close()
+	if timeout > 0 {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_INFO, fmt.Sprintf("Tempo limite de execução: %s", timeout)))
+	}
+	if !limits.empty() {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_INFO, "Limites de recursos: "+limits.String()))
+	}
+	for _, note := range enforcer.notes {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_INFO, note))
+	}
+
+	if err := streamCommand(cmd, pb.DeployPhase_DEPLOY_PHASE_RUN, manifest.charset, logStream, enforcer.started, enforcer.observe); err != nil {
+		if job.ctx.Err() != nil {
+			return err
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			s.setLimitExceeded(job, limitTimeout)
+			logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Tempo limite de %s excedido; bot encerrado.", timeout)))
+			return fmt.Errorf("tempo limite de %s excedido: %w", timeout, err)
+		}
+		if limit := enforcer.exceeded(); limit != "" {
+			s.setLimitExceeded(job, limit)
+			what := fmt.Sprintf("limite de memória de %s", config.FormatBytes(limits.MemoryBytes))
+			if limit == limitOpenFiles {
+				what = fmt.Sprintf("limite de %d arquivos abertos", limits.OpenFiles)
 			}
+			logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Bot encerrado por exceder o %s.", what)))
+			return fmt.Errorf("%s excedido: %w", what, err)
 		}
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_ERROR, fmt.Sprintf("Erro durante a execução do bot: %v", err)))
+		return err
 	}
 
-	wg.Add(2)
-	go senLogs(stdout)
-	go senLogs(stderr)
-
-	cmdErr := cmd.Wait()
-	wg.Wait() // Aguarda todas as goroutines terminarem de ler
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_RUN, pb.LogStatus_LOG_STATUS_SUCCESS, "Bot executado com sucesso!"))
+	return nil
+}
 
-	if cmdErr != nil {
-		logStream <- &pb.LogResponse{Line: fmt.Sprintf("Erro durante a execução do bot: %v", cmdErr), Status: "ERROR"}
-		return cmdErr
+// streamCommand executa cmd repassando cada linha de stdout e stderr,
+// decodificada de charset, para o stream de logs e espera toda a saída ser
+// lida antes de retornar. Se informado, started é chamado logo após o Start
+// com o pid do processo; se falhar, o processo é encerrado. onLine, se não
+// for nil, também recebe cada linha.
+func streamCommand(cmd *exec.Cmd, phase pb.DeployPhase, charset outputCharset, logStream *logSink, started func(pid int) error, onLine func(pb.LogSource, outputLine)) error {
+	waitOutput := logStream.attachOutput(cmd, phase, charset, onLine)
+	if err := cmd.Start(); err != nil {
+		waitOutput()
+		return fmt.Errorf("falha ao iniciar %s: %v", filepath.Base(cmd.Path), err)
 	}
-
-	logStream <- &pb.LogResponse{Line: "Bot executado com sucesso!", Status: "SUCCESS"}
-	return nil
+	if started != nil {
+		if err := started(cmd.Process.Pid); err != nil {
+			signalProcessTree(cmd.Process, true)
+			cmd.Wait()
+			waitOutput()
+			return err
+		}
+	}
+	cmdErr := cmd.Wait()
+	waitOutput()
+	return cmdErr
 }
 
-func (s *OrchestratorService) installRequirements(bot *structs.Bot, logStream chan<- *pb.LogResponse) error {
-	basePath := fmt.Sprintf("./bots/%s/%s", bot.BotID, bot.Version)
+// installRequirements prepara o ambiente virtual do bot e devolve o seu
+// caminho, ou "" quando o bot não tem dependências. Os ambientes ficam em
+// cache pela chave de envCacheKey, então uma reexecução sem mudanças nas
+// dependências não instala nada.
+func (s *OrchestratorService) installRequirements(job *Job, manifest *botManifest, logStream *logSink) (venvPath string, err error) {
+	bot := &job.Bot
+	basePath := s.versionDir(bot)
 	sourceDir, _ := filepath.Abs(filepath.Join(basePath, "source"))
-	venvPath, _ := filepath.Abs(filepath.Join(basePath, "venv"))
-	reqFile := filepath.Join(sourceDir, "requirements.txt")
-
-	if _, err := os.Stat(reqFile); os.IsNotExist(err) {
-		logStream <- &pb.LogResponse{Line: "requirements.txt não encontrado em 'source/'. Pulando.", Status: "INFO"}
-		return nil
-	}
-	cmd := exec.Command("python", "-m", "venv", venvPath)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("erro ao criar ambiente virtual: %v", err)
-	}
-	logStream <- &pb.LogResponse{Line: "Ambiente virtual criado com sucesso.", Status: "SUCCESS"}
-
-	pipPath := filepath.Join(venvPath, "Scripts", "pip.exe")
-	installCmd := exec.Command(pipPath, "install", "-r", reqFile)
-	installCmd.Dir = sourceDir
-	stdout, _ := installCmd.StdoutPipe()
-	stderr, _ := installCmd.StderrPipe()
-	if err := installCmd.Start(); err != nil {
-		return fmt.Errorf("falha ao iniciar instalação de dependências: %v", err)
-	}
-	var wg sync.WaitGroup
-	senLogs := func(r io.Reader) {
-		defer wg.Done()
-		scanner := bufio.NewScanner(r)
-		for scanner.Scan() {
-			logStream <- &pb.LogResponse{
-				Line:   sanitizeUTF8(scanner.Text()),
-				Status: "INFO",
+	// Versões antigas do agente criavam o venv dentro do diretório da versão.
+	os.RemoveAll(filepath.Join(basePath, "venv"))
+
+	depName := manifest.Dependencies
+	if depName == "" {
+		depName = "requirements.txt"
+	}
+	depFile := filepath.Join(sourceDir, depName)
+	if _, err := os.Stat(depFile); os.IsNotExist(err) {
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_INFO, fmt.Sprintf("%s não encontrado em 'source/'. Pulando.", filepath.ToSlash(depName))))
+		return "", nil
+	}
+	basePython, err := resolvePythonVersion(s.pythonBin, manifest.Python)
+	if err != nil {
+		return "", err
+	}
+	version, err := pythonVersion(basePython)
+	if err != nil {
+		return "", err
+	}
+	key, err := envCacheKey(depFile, version, sourceDir, job.ResolvedCommit)
+	if err != nil {
+		return "", err
+	}
+	venvPath = s.venvCachePath(key)
+
+	// Jobs de bots diferentes podem pedir o mesmo ambiente ao mesmo tempo;
+	// só um deles o cria.
+	lock, unref := s.versionLock("venv:" + key)
+	select {
+	case lock <- struct{}{}:
+	case <-job.ctx.Done():
+		unref()
+		return "", job.ctx.Err()
+	}
+	defer func() {
+		<-lock
+		unref()
+	}()
+
+	if entry, ok := readEnvCache(venvPath, key); ok {
+		touchEnvCache(venvPath)
+		s.setVenvKey(job, key)
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_SUCCESS,
+			fmt.Sprintf("Cache de dependências: hit (%.12s, Python %s, criado em %s por %s/%s). Instalação pulada.",
+				key, entry.Python, entry.CreatedAt.Local().Format("2006-01-02 15:04"), entry.BotID, entry.Version)))
+		return venvPath, nil
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_INFO,
+		fmt.Sprintf("Cache de dependências: miss (%.12s, Python %s). Criando o ambiente.", key, version)))
+
+	ctx := job.ctx
+	if s.installTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(job.ctx, s.installTimeout)
+		defer cancel()
+		defer func() {
+			if err != nil && job.ctx.Err() == nil && ctx.Err() == context.DeadlineExceeded {
+				s.setLimitExceeded(job, limitInstallTimeout)
+				err = fmt.Errorf("tempo limite de instalação de %s excedido: %v", s.installTimeout, err)
 			}
-		}
+		}()
 	}
+	// Um ambiente pela metade não fica no cache.
+	defer func() {
+		if err != nil {
+			os.RemoveAll(venvPath)
+		}
+	}()
 
-	wg.Add(2)
-	go senLogs(stdout)
-	go senLogs(stderr)
+	if err := os.RemoveAll(venvPath); err != nil {
+		return "", fmt.Errorf("erro ao remover o ambiente virtual: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(venvPath), 0755); err != nil {
+		return "", fmt.Errorf("erro ao criar o diretório de cache: %v", err)
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_INFO, fmt.Sprintf("Criando ambiente virtual com %s", basePython)))
 
-	cmdErr := installCmd.Wait()
-	wg.Wait()
+	cmd := exec.CommandContext(ctx, basePython, "-m", "venv", venvPath)
+	bindProcessTree(cmd)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("erro ao criar ambiente virtual: %v - %s", err, strings.TrimSpace(sanitizeUTF8(string(output))))
+	}
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_SUCCESS, "Ambiente virtual criado com sucesso."))
+
+	pipPath := venvExecutable(venvPath, "pip")
+	pipEnv := append(os.Environ(), s.pipCacheEnv()...)
+	depDir := filepath.Dir(depFile)
+	var installCmd *exec.Cmd
+	switch dependencyKind(depFile) {
+	case "pyproject":
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_INFO, fmt.Sprintf("Instalando o projeto de %s", depName)))
+		installCmd = exec.CommandContext(ctx, pipPath, "install", depDir)
+		installCmd.Env = pipEnv
+	case "pipfile":
+		// O pip não lê Pipfile: o pipenv é instalado no próprio venv e, com
+		// VIRTUAL_ENV definido, instala as dependências nele.
+		logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_INFO, "Instalando pipenv para ler o Pipfile"))
+		pipenvCmd := exec.CommandContext(ctx, pipPath, "install", "pipenv")
+		pipenvCmd.Env = pipEnv
+		bindProcessTree(pipenvCmd)
+		if err := streamCommand(pipenvCmd, pb.DeployPhase_DEPLOY_PHASE_INSTALL, charsetAuto, logStream, nil, nil); err != nil {
+			return "", fmt.Errorf("erro ao instalar o pipenv: %v", err)
+		}
+		args := []string{"install"}
+		if _, err := os.Stat(filepath.Join(depDir, "Pipfile.lock")); err == nil {
+			args = append(args, "--deploy")
+		}
+		installCmd = exec.CommandContext(ctx, venvExecutable(venvPath, "pipenv"), args...)
+		installCmd.Env = append(pipEnv, "VIRTUAL_ENV="+venvPath, "PIPENV_VERBOSITY=-1", "PIPENV_YES=1")
+	default:
+		installCmd = exec.CommandContext(ctx, pipPath, "install", "-r", depFile)
+		installCmd.Env = pipEnv
+	}
+	bindProcessTree(installCmd)
+	installCmd.Dir = depDir
 
-	if cmdErr != nil {
-		return fmt.Errorf("erro durante a instalação de dependências: %v", cmdErr)
+	if err := streamCommand(installCmd, pb.DeployPhase_DEPLOY_PHASE_INSTALL, charsetAuto, logStream, nil, nil); err != nil {
+		return "", fmt.Errorf("erro durante a instalação de dependências: %v", err)
 	}
-	logStream <- &pb.LogResponse{Line: "Dependências instaladas com sucesso.", Status: "SUCCESS"}
-	return nil
+	err = writeEnvCache(venvPath, envCacheEntry{
+		Key:          key,
+		Python:       version,
+		Dependencies: depName,
+		BotID:        bot.BotID,
+		Version:      bot.Version,
+		CreatedAt:    time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	s.setVenvKey(job, key)
+	logStream.send(newLog(pb.DeployPhase_DEPLOY_PHASE_INSTALL, pb.LogStatus_LOG_STATUS_SUCCESS, "Dependências instaladas com sucesso."))
+	return venvPath, nil
 }
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"fmt"
+	"orchestrator/pb"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain identifica os erros do agente no errdetails.ErrorInfo.
+const errorDomain = "orchestrator.gobot"
+
+// failureCodes associa cada motivo de falha a um código gRPC distinto.
+var failureCodes = map[pb.FailureReason]codes.Code{
+	pb.FailureReason_FAILURE_REASON_CANCELLED:      codes.Canceled,
+	pb.FailureReason_FAILURE_REASON_INVALID_CONFIG: codes.FailedPrecondition,
+	pb.FailureReason_FAILURE_REASON_CLONE:          codes.Unavailable,
+	pb.FailureReason_FAILURE_REASON_DEPENDENCIES:   codes.Aborted,
+	pb.FailureReason_FAILURE_REASON_BOT_EXIT:       codes.Unknown,
+	pb.FailureReason_FAILURE_REASON_TIMEOUT:        codes.DeadlineExceeded,
+	pb.FailureReason_FAILURE_REASON_RESOURCE_LIMIT: codes.ResourceExhausted,
+}
+
+// summary monta o resumo de um job finalizado. Chamado com s.mu travado.
+func (j *Job) summary() *pb.JobSummary {
+	return &pb.JobSummary{
+		State:           j.State,
+		Phase:           j.Phase,
+		FailureReason:   j.FailureReason,
+		ExitCode:        int32(j.ExitCode),
+		Signal:          j.Signal,
+		Error:           j.Err,
+		ResolvedCommit:  j.ResolvedCommit,
+		LimitExceeded:   j.LimitExceeded,
+		QueuedDuration:  durationpb.New(j.durations[pb.JobState_JOB_STATE_QUEUED]),
+		CloneDuration:   durationpb.New(j.durations[pb.JobState_JOB_STATE_CLONING]),
+		InstallDuration: durationpb.New(j.durations[pb.JobState_JOB_STATE_INSTALLING]),
+		RunDuration:     durationpb.New(j.durations[pb.JobState_JOB_STATE_RUNNING]),
+		TotalDuration:   durationpb.New(j.FinishedAt.Sub(j.StartedAt)),
+	}
+}
+
+// status converte a falha do job em um status gRPC com um ErrorInfo e o
+// próprio resumo nos detalhes. Chamado com s.mu travado.
+func (j *Job) status() *status.Status {
+	return summaryStatus(j.ID, j.summary())
+}
+
+// summaryStatus monta o status de um job a partir do resumo, que também é o
+// que fica gravado no fim do log do job.
+func summaryStatus(jobID string, summary *pb.JobSummary) *status.Status {
+	code, ok := failureCodes[summary.FailureReason]
+	if !ok {
+		code = codes.Unknown
+	}
+	st := status.New(code, summary.Error)
+	info := &errdetails.ErrorInfo{
+		Reason: strings.TrimPrefix(summary.FailureReason.String(), "FAILURE_REASON_"),
+		Domain: errorDomain,
+		Metadata: map[string]string{
+			"job_id":    jobID,
+			"phase":     strings.TrimPrefix(summary.Phase.String(), "DEPLOY_PHASE_"),
+			"exit_code": strconv.Itoa(int(summary.ExitCode)),
+		},
+	}
+	if summary.Signal != "" {
+		info.Metadata["signal"] = summary.Signal
+	}
+	if summary.ResolvedCommit != "" {
+		info.Metadata["resolved_commit"] = summary.ResolvedCommit
+	}
+	if withDetails, err := st.WithDetails(info, summary); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// summaryLog é a última mensagem do stream do job: uma linha legível para o
+// log persistido e o JobSummary no oneof terminal.
+func (s *OrchestratorService) summaryLog(job *Job) *pb.LogResponse {
+	s.mu.Lock()
+	summary := job.summary()
+	s.mu.Unlock()
+
+	logStatus := pb.LogStatus_LOG_STATUS_ERROR
+	switch summary.State {
+	case pb.JobState_JOB_STATE_SUCCEEDED:
+		logStatus = pb.LogStatus_LOG_STATUS_SUCCESS
+	case pb.JobState_JOB_STATE_CANCELLED:
+		logStatus = pb.LogStatus_LOG_STATUS_CANCELLED
+	}
+	msg := newLog(summary.Phase, logStatus, summaryLine(summary))
+	msg.Terminal = &pb.LogResponse_Summary{Summary: summary}
+	return msg
+}
+
+// summaryLine descreve o resumo em uma linha, ex: "Job FAILED na fase RUN
+// (código de saída 1) em 12.4s: clone 1.2s, instalação 3.1s, execução 8.1s".
+func summaryLine(summary *pb.JobSummary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Job %s", strings.TrimPrefix(summary.State.String(), "JOB_STATE_"))
+	if summary.Phase != pb.DeployPhase_DEPLOY_PHASE_UNSPECIFIED {
+		fmt.Fprintf(&b, " na fase %s", strings.TrimPrefix(summary.Phase.String(), "DEPLOY_PHASE_"))
+	}
+	switch {
+	case summary.Signal != "":
+		fmt.Fprintf(&b, " (sinal %s)", summary.Signal)
+	case summary.ExitCode >= 0:
+		fmt.Fprintf(&b, " (código de saída %d)", summary.ExitCode)
+	}
+	fmt.Fprintf(&b, " em %s", roundDuration(summary.TotalDuration.AsDuration()))
+
+	var phases []string
+	for _, phase := range []struct {
+		name     string
+		duration *durationpb.Duration
+	}{
+		{"fila", summary.QueuedDuration},
+		{"clone", summary.CloneDuration},
+		{"instalação", summary.InstallDuration},
+		{"execução", summary.RunDuration},
+	} {
+		if d := phase.duration.AsDuration(); d >= time.Millisecond {
+			phases = append(phases, phase.name+" "+roundDuration(d))
+		}
+	}
+	if len(phases) > 0 {
+		b.WriteString(": " + strings.Join(phases, ", "))
+	}
+	if summary.ResolvedCommit != "" {
+		fmt.Fprintf(&b, " (commit %.12s)", summary.ResolvedCommit)
+	}
+	return b.String()
+}
+
+func roundDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,214 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Expression é uma expressão cron de 5 campos (minuto, hora, dia do mês, mês
+// e dia da semana) já interpretada.
+type Expression struct {
+	minute, hour, dom, month, dow uint64
+	domStar, dowStar              bool
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 também é aceito como domingo e convertido para 0.
+	dowField = cronField{min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse interpreta uma expressão cron padrão, ex: "0 8 * * MON-FRI" (dias
+// úteis às 08:00) ou "*/15 * * * *" (a cada 15 minutos).
+func Parse(expr string) (*Expression, error) {
+	expr = strings.TrimSpace(expr)
+	if macro, ok := macros[strings.ToLower(expr)]; ok {
+		expr = macro
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expressão cron deve ter 5 campos, recebido %d: %q", len(fields), expr)
+	}
+
+	e := &Expression{
+		domStar: strings.HasPrefix(fields[2], "*"),
+		dowStar: strings.HasPrefix(fields[4], "*"),
+	}
+	var err error
+	if e.minute, err = minuteField.parse(fields[0]); err != nil {
+		return nil, fmt.Errorf("minuto: %v", err)
+	}
+	if e.hour, err = hourField.parse(fields[1]); err != nil {
+		return nil, fmt.Errorf("hora: %v", err)
+	}
+	if e.dom, err = domField.parse(fields[2]); err != nil {
+		return nil, fmt.Errorf("dia do mês: %v", err)
+	}
+	if e.month, err = monthField.parse(fields[3]); err != nil {
+		return nil, fmt.Errorf("mês: %v", err)
+	}
+	if e.dow, err = dowField.parse(fields[4]); err != nil {
+		return nil, fmt.Errorf("dia da semana: %v", err)
+	}
+	if e.dow&(1<<7) != 0 {
+		e.dow = e.dow&^(1<<7) | 1
+	}
+	return e, nil
+}
+
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("passo inválido %q", stepPart)
+			}
+			step = n
+		}
+
+		var start, end int
+		switch {
+		case rangePart == "*":
+			start, end = f.min, f.max
+		case strings.Contains(rangePart, "-"):
+			lo, hi, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = f.value(lo); err != nil {
+				return 0, err
+			}
+			if end, err = f.value(hi); err != nil {
+				return 0, err
+			}
+		default:
+			v, err := f.value(rangePart)
+			if err != nil {
+				return 0, err
+			}
+			start, end = v, v
+			if hasStep {
+				end = f.max
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("intervalo invertido %q", rangePart)
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("valor %d fora do intervalo %d-%d", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+func has(bits uint64, v int) bool {
+	return bits&(1<<uint(v)) != 0
+}
+
+// dayMatches segue a semântica do cron: se dia do mês e dia da semana forem
+// ambos restritos, basta um deles coincidir.
+func (e *Expression) dayMatches(t time.Time) bool {
+	domMatch := has(e.dom, t.Day())
+	dowMatch := has(e.dow, int(t.Weekday()))
+	if e.domStar || e.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// maxNextSteps limita as iterações de Next. Uma busca de cinco anos para
+// uma expressão que nunca dispara leva poucos milhares de passos.
+const maxNextSteps = 100000
+
+// Next devolve o primeiro instante estritamente posterior a t que satisfaz
+// a expressão, no fuso de t. Devolve o tempo zero se não houver ocorrência
+// nos próximos cinco anos (ex: "0 0 30 2 *").
+//
+// Horas e minutos avançam em tempo absoluto e os campos são conferidos no
+// horário local de cada instante. Assim, um horário pulado pelo início do
+// horário de verão não dispara naquele dia, e um horário repetido no fim
+// dele dispara nas duas vezes em que acontece.
+func (e *Expression) Next(t time.Time) time.Time {
+	loc := t.Location()
+	after := t
+	t = t.Add(time.Minute - time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + 5
+
+	for i := 0; i < maxNextSteps && t.Year() <= yearLimit; i++ {
+		switch {
+		case !has(e.month, int(t.Month())):
+			t = wallTime(t.Year(), t.Month()+1, 1, loc)
+		case !e.dayMatches(t):
+			t = wallTime(t.Year(), t.Month(), t.Day()+1, loc)
+		case !has(e.hour, t.Hour()):
+			t = t.Add(time.Hour - time.Duration(t.Minute())*time.Minute)
+		case !has(e.minute, t.Minute()) || !t.After(after):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// wallTime devolve o início do dia pedido em loc. Quando a meia-noite não
+// existe (horário de verão começando à 00:00), devolve o primeiro instante
+// do dia, no fim do intervalo pulado.
+func wallTime(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	want := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	// Para um horário inexistente, time.Date pode devolver um instante antes
+	// do intervalo pulado.
+	for i := 0; i < 24*60 && wallClock(t).Before(want); i++ {
+		t = t.Add(time.Minute)
+	}
+	return t
+}
+
+// wallClock devolve o horário local de t como se fosse UTC, para comparar
+// horários de parede sem a influência do fuso.
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
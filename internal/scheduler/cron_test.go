@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpressionNext(t *testing.T) {
+	tests := []struct {
+		name string
+		cron string
+		tz   string
+		from string
+		want string // vazio quando a expressão nunca dispara
+	}{
+		{
+			name: "a cada 15 minutos",
+			cron: "*/15 * * * *", tz: "UTC",
+			from: "2026-01-10T10:07:30Z", want: "2026-01-10T10:15:00Z",
+		},
+		{
+			name: "estritamente depois de t",
+			cron: "*/15 * * * *", tz: "UTC",
+			from: "2026-01-10T10:15:00Z", want: "2026-01-10T10:30:00Z",
+		},
+		{
+			name: "dias úteis pula o fim de semana",
+			cron: "0 8 * * MON-FRI", tz: "UTC",
+			from: "2026-01-09T09:00:00Z", want: "2026-01-12T08:00:00Z",
+		},
+		{
+			name: "virada de ano",
+			cron: "@yearly", tz: "UTC",
+			from: "2026-06-01T00:00:00Z", want: "2027-01-01T00:00:00Z",
+		},
+		{
+			name: "nunca dispara",
+			cron: "0 0 30 2 *", tz: "UTC",
+			from: "2026-01-01T00:00:00Z", want: "",
+		},
+
+		// Nova York: em 2026-03-08 o relógio pula de 02:00 EST para 03:00 EDT.
+		{
+			name: "NY início do horário de verão, horário depois do intervalo",
+			cron: "0 5 * * *", tz: "America/New_York",
+			from: "2026-03-08T00:00:00-05:00", want: "2026-03-08T05:00:00-04:00",
+		},
+		{
+			name: "NY início do horário de verão, intervalo a cada 15 minutos",
+			cron: "*/15 * * * *", tz: "America/New_York",
+			from: "2026-03-08T01:50:00-05:00", want: "2026-03-08T03:00:00-04:00",
+		},
+		{
+			name: "NY início do horário de verão, horário pulado",
+			cron: "30 2 * * *", tz: "America/New_York",
+			from: "2026-03-07T03:00:00-05:00", want: "2026-03-09T02:30:00-04:00",
+		},
+
+		// Nova York: em 2026-11-01 o relógio volta de 02:00 EDT para 01:00 EST.
+		{
+			name: "NY fim do horário de verão, primeira passagem",
+			cron: "*/15 * * * *", tz: "America/New_York",
+			from: "2026-11-01T01:50:00-04:00", want: "2026-11-01T01:00:00-05:00",
+		},
+		{
+			name: "NY fim do horário de verão, segunda passagem",
+			cron: "*/15 * * * *", tz: "America/New_York",
+			from: "2026-11-01T01:50:00-05:00", want: "2026-11-01T02:00:00-05:00",
+		},
+		{
+			name: "NY fim do horário de verão, horário repetido dispara de novo",
+			cron: "30 1 * * *", tz: "America/New_York",
+			from: "2026-11-01T01:30:00-04:00", want: "2026-11-01T01:30:00-05:00",
+		},
+
+		// São Paulo: em 2018-11-04 o relógio pulou de 00:00 para 01:00, então
+		// aquele dia não teve meia-noite.
+		{
+			name: "SP início do horário de verão, meia-noite pulada",
+			cron: "0 0 * * *", tz: "America/Sao_Paulo",
+			from: "2018-11-03T12:00:00-03:00", want: "2018-11-05T00:00:00-02:00",
+		},
+		{
+			name: "SP início do horário de verão, primeira hora do dia",
+			cron: "0 1 * * *", tz: "America/Sao_Paulo",
+			from: "2018-11-03T12:00:00-03:00", want: "2018-11-04T01:00:00-02:00",
+		},
+		{
+			name: "SP início do horário de verão, dia específico",
+			cron: "0 12 4 11 *", tz: "America/Sao_Paulo",
+			from: "2018-11-01T00:00:00-03:00", want: "2018-11-04T12:00:00-02:00",
+		},
+
+		// São Paulo: em 2019-02-17 o relógio voltou de 00:00 para 23:00 do
+		// dia 16, que teve duas vezes 23:00-23:59.
+		{
+			name: "SP fim do horário de verão, horário repetido",
+			cron: "30 23 * * *", tz: "America/Sao_Paulo",
+			from: "2019-02-16T23:40:00-02:00", want: "2019-02-16T23:30:00-03:00",
+		},
+		{
+			name: "SP fim do horário de verão, meia-noite seguinte",
+			cron: "0 0 * * *", tz: "America/Sao_Paulo",
+			from: "2019-02-16T22:00:00-02:00", want: "2019-02-17T00:00:00-03:00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := time.LoadLocation(tt.tz)
+			if err != nil {
+				t.Skipf("fuso %s indisponível: %v", tt.tz, err)
+			}
+			expr, err := Parse(tt.cron)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.cron, err)
+			}
+			from, err := time.Parse(time.RFC3339, tt.from)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := expr.Next(from.In(loc))
+			if tt.want == "" {
+				if !got.IsZero() {
+					t.Fatalf("Next(%s) = %s, esperado tempo zero", tt.from, got.Format(time.RFC3339))
+				}
+				return
+			}
+			if got.Format(time.RFC3339) != tt.want {
+				t.Fatalf("Next(%s) = %s, esperado %s", tt.from, got.Format(time.RFC3339), tt.want)
+			}
+		})
+	}
+}
+
+// Uma sequência de chamadas atravessando as mudanças de horário deve sempre
+// avançar, sem repetir nem voltar no tempo.
+func TestExpressionNextAdvances(t *testing.T) {
+	for _, tz := range []string{"America/New_York", "America/Sao_Paulo"} {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			t.Skipf("fuso %s indisponível: %v", tz, err)
+		}
+		for _, cron := range []string{"*/15 * * * *", "0 * * * *", "0 0 * * *", "30 2 * * *"} {
+			expr, err := Parse(cron)
+			if err != nil {
+				t.Fatal(err)
+			}
+			at := time.Date(2018, time.October, 1, 0, 0, 0, 0, loc)
+			end := at.AddDate(0, 6, 0)
+			for at.Before(end) {
+				next := expr.Next(at)
+				if !next.After(at) {
+					t.Fatalf("%s em %s: Next(%s) = %s não avança", cron, tz, at.Format(time.RFC3339), next.Format(time.RFC3339))
+				}
+				at = next
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"* * * FOO *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) aceitou uma expressão inválida", expr)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"orchestrator/structs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MisfirePolicy define o que fazer com uma execução que deveria ter
+// acontecido enquanto o agente estava parado (ou atrasou mais que
+// misfireGrace).
+type MisfirePolicy string
+
+const (
+	// MisfireSkip descarta as execuções perdidas e espera a próxima ocorrência.
+	MisfireSkip MisfirePolicy = "skip"
+	// MisfireRunOnce executa uma única vez assim que possível, não importa
+	// quantas ocorrências tenham sido perdidas.
+	MisfireRunOnce MisfirePolicy = "run_once"
+)
+
+// misfireGrace é o atraso tolerado antes de uma execução ser considerada
+// perdida.
+const misfireGrace = time.Minute
+
+var (
+	ErrScheduleNotFound = errors.New("agendamento não encontrado")
+	ErrInvalidSchedule  = errors.New("agendamento inválido")
+)
+
+type Schedule struct {
+	ID        string        `json:"id"`
+	Cron      string        `json:"cron"`
+	Timezone  string        `json:"timezone,omitempty"`
+	Bot       structs.Bot   `json:"bot"`
+	Misfire   MisfirePolicy `json:"misfire_policy"`
+	Paused    bool          `json:"paused"`
+	CreatedAt time.Time     `json:"created_at"`
+	NextRunAt time.Time     `json:"next_run_at"`
+	LastRunAt time.Time     `json:"last_run_at"`
+	LastJobID string        `json:"last_job_id,omitempty"`
+	LastError string        `json:"last_error,omitempty"`
+	CreatedBy string        `json:"created_by,omitempty"`
+
+	expr *Expression
+	loc  *time.Location
+}
+
+// TriggerFunc cria o job de uma execução agendada e devolve o id do job.
+type TriggerFunc func(sched Schedule) (string, error)
+
+// Scheduler dispara jobs a partir de expressões cron e persiste os
+// agendamentos em um arquivo JSON para sobreviver a reinícios do agente.
+type Scheduler struct {
+	mu        sync.Mutex
+	path      string
+	schedules map[string]*Schedule
+	trigger   TriggerFunc
+	wake      chan struct{}
+}
+
+// New carrega os agendamentos salvos em path (se existir).
+func New(path string, trigger TriggerFunc) (*Scheduler, error) {
+	s := &Scheduler{
+		path:      path,
+		schedules: make(map[string]*Schedule),
+		trigger:   trigger,
+		wake:      make(chan struct{}, 1),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler agendamentos: %v", err)
+	}
+
+	var saved []*Schedule
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("arquivo de agendamentos inválido: %v", err)
+	}
+	for _, sched := range saved {
+		if err := sched.compile(); err != nil {
+			return nil, fmt.Errorf("agendamento %s inválido: %v", sched.ID, err)
+		}
+		s.schedules[sched.ID] = sched
+	}
+	return s, nil
+}
+
+func (sched *Schedule) compile() error {
+	expr, err := Parse(sched.Cron)
+	if err != nil {
+		return err
+	}
+	loc := time.Local
+	if sched.Timezone != "" {
+		if loc, err = time.LoadLocation(sched.Timezone); err != nil {
+			return fmt.Errorf("fuso horário inválido %q: %v", sched.Timezone, err)
+		}
+	}
+	switch sched.Misfire {
+	case "":
+		sched.Misfire = MisfireSkip
+	case MisfireSkip, MisfireRunOnce:
+	default:
+		return fmt.Errorf("política de misfire inválida %q", sched.Misfire)
+	}
+	sched.expr = expr
+	sched.loc = loc
+	return nil
+}
+
+func (sched *Schedule) next(after time.Time) time.Time {
+	return sched.expr.Next(after.In(sched.loc))
+}
+
+func newScheduleID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(b)
+}
+
+// Create valida e registra um novo agendamento. createdBy é a identidade de
+// quem o criou, herdada pelos jobs disparados.
+func (s *Scheduler) Create(cron, timezone string, bot structs.Bot, misfire MisfirePolicy, createdBy string) (Schedule, error) {
+	sched := &Schedule{
+		ID:        newScheduleID(),
+		Cron:      cron,
+		Timezone:  timezone,
+		Bot:       bot,
+		Misfire:   misfire,
+		CreatedAt: time.Now(),
+		CreatedBy: createdBy,
+	}
+	if err := sched.compile(); err != nil {
+		return Schedule{}, fmt.Errorf("%w: %v", ErrInvalidSchedule, err)
+	}
+	sched.NextRunAt = sched.next(sched.CreatedAt)
+	if sched.NextRunAt.IsZero() {
+		return Schedule{}, fmt.Errorf("%w: expressão cron %q nunca dispara", ErrInvalidSchedule, cron)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules[sched.ID] = sched
+	if err := s.saveLocked(); err != nil {
+		delete(s.schedules, sched.ID)
+		return Schedule{}, err
+	}
+	s.notify()
+	return *sched, nil
+}
+
+// List devolve os agendamentos ordenados pela data de criação.
+func (s *Scheduler) List() []Schedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		list = append(list, *sched)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list
+}
+
+// Get devolve o agendamento id.
+func (s *Scheduler) Get(id string) (Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sched, ok := s.schedules[id]
+	if !ok {
+		return Schedule{}, ErrScheduleNotFound
+	}
+	return *sched, nil
+}
+
+func (s *Scheduler) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sched, ok := s.schedules[id]
+	if !ok {
+		return ErrScheduleNotFound
+	}
+	delete(s.schedules, id)
+	if err := s.saveLocked(); err != nil {
+		s.schedules[id] = sched
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// SetPaused pausa ou retoma um agendamento. Ao retomar, as ocorrências que
+// caíram durante a pausa não são executadas.
+func (s *Scheduler) SetPaused(id string, paused bool) (Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sched, ok := s.schedules[id]
+	if !ok {
+		return Schedule{}, ErrScheduleNotFound
+	}
+	if sched.Paused != paused {
+		sched.Paused = paused
+		if !paused {
+			sched.NextRunAt = sched.next(time.Now())
+		}
+		if err := s.saveLocked(); err != nil {
+			return Schedule{}, err
+		}
+		s.notify()
+	}
+	return *sched, nil
+}
+
+// Run dispara os agendamentos até ctx ser cancelado.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		s.fireDue(time.Now())
+
+		timer := time.NewTimer(s.untilNext(time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// untilNext devolve quanto esperar até a próxima execução; nunca mais que um
+// minuto, para tolerar ajustes no relógio do sistema.
+func (s *Scheduler) untilNext(now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	wait := time.Minute
+	for _, sched := range s.schedules {
+		if sched.Paused || sched.NextRunAt.IsZero() {
+			continue
+		}
+		if d := sched.NextRunAt.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
+func (s *Scheduler) fireDue(now time.Time) {
+	s.mu.Lock()
+	var due []*Schedule
+	changed := false
+	for _, sched := range s.schedules {
+		if sched.Paused || sched.NextRunAt.IsZero() || sched.NextRunAt.After(now) {
+			continue
+		}
+		missed := now.Sub(sched.NextRunAt) > misfireGrace
+		if missed && sched.Misfire == MisfireSkip {
+			log.Printf("[scheduler] %s: execução de %s perdida, aguardando a próxima", sched.ID, sched.NextRunAt.Format(time.RFC3339))
+		} else {
+			due = append(due, sched)
+		}
+		sched.NextRunAt = sched.next(now)
+		changed = true
+	}
+	s.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	for _, sched := range due {
+		s.mu.Lock()
+		snapshot := *sched
+		s.mu.Unlock()
+		jobID, err := s.trigger(snapshot)
+
+		s.mu.Lock()
+		sched.LastRunAt = now
+		sched.LastJobID = jobID
+		sched.LastError = ""
+		if err != nil {
+			sched.LastError = err.Error()
+			log.Printf("[scheduler] %s: falha ao criar job: %v", sched.ID, err)
+		}
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.saveLocked(); err != nil {
+		log.Printf("[scheduler] erro ao salvar agendamentos: %v", err)
+	}
+}
+
+// saveLocked grava os agendamentos de forma atômica (arquivo temporário +
+// rename). Deve ser chamado com s.mu travado.
+func (s *Scheduler) saveLocked() error {
+	list := make([]*Schedule, 0, len(s.schedules))
+	for _, sched := range s.schedules {
+		list = append(list, sched)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de agendamentos: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("erro ao salvar agendamentos: %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,264 @@
+// Package secrets guarda os segredos injetados nos bots (chaves de API,
+// senhas de banco, ...) em um arquivo criptografado com AES-256-GCM. A chave
+// fica em um arquivo local separado, criado no primeiro uso. Cada segredo
+// pode ser restrito a alguns bots.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"orchestrator/internal/validation"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const keySize = 32
+
+var (
+	ErrSecretNotFound   = errors.New("segredo não encontrado")
+	ErrSecretNotAllowed = errors.New("segredo não liberado para o bot")
+)
+
+// sealedSecret é uma entrada do arquivo: o valor cifrado e os padrões
+// (path.Match) dos bots que podem usá-lo. Sem bots, a entrada é gravada só
+// como o valor cifrado, o formato de antes da restrição existir.
+type sealedSecret struct {
+	Value string   `json:"value"`
+	Bots  []string `json:"bots,omitempty"`
+}
+
+func (e sealedSecret) MarshalJSON() ([]byte, error) {
+	if len(e.Bots) == 0 {
+		return json.Marshal(e.Value)
+	}
+	type plain sealedSecret
+	return json.Marshal(plain(e))
+}
+
+func (e *sealedSecret) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*e = sealedSecret{}
+		return json.Unmarshal(data, &e.Value)
+	}
+	type plain sealedSecret
+	return json.Unmarshal(data, (*plain)(e))
+}
+
+// additionalData amarra o valor cifrado ao nome e aos bots do segredo, para
+// que nenhum dos dois possa ser trocado no arquivo.
+func additionalData(name string, bots []string) []byte {
+	if len(bots) == 0 {
+		return []byte(name)
+	}
+	return []byte(name + "\x00" + strings.Join(bots, "\x00"))
+}
+
+// Info descreve um segredo cadastrado, sem o valor.
+type Info struct {
+	Name string
+	Bots []string
+}
+
+// Store lê e grava segredos em path, criptografados com a chave de keyPath.
+// Cada valor é cifrado separadamente, com o nome e os bots do segredo como
+// dado associado.
+type Store struct {
+	mu      sync.Mutex
+	path    string
+	keyPath string
+}
+
+func NewStore(path, keyPath string) *Store {
+	return &Store{path: path, keyPath: keyPath}
+}
+
+// loadKey lê a chave local; se ela não existir e create for verdadeiro, gera
+// uma nova.
+func (s *Store) loadKey(create bool) ([]byte, error) {
+	key, err := os.ReadFile(s.keyPath)
+	if err == nil {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("chave de segredos inválida em %s", s.keyPath)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) || !create {
+		return nil, fmt.Errorf("erro ao ler a chave de segredos: %v", err)
+	}
+
+	key = make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0700); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório da chave: %v", err)
+	}
+	// O_EXCL evita sobrescrever a chave de outro processo criada no meio tempo.
+	f, err := os.OpenFile(s.keyPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar a chave de segredos: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(key); err != nil {
+		return nil, fmt.Errorf("erro ao gravar a chave de segredos: %v", err)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// readAll devolve as entradas ainda cifradas, por nome.
+func (s *Store) readAll() (map[string]sealedSecret, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return map[string]sealedSecret{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler segredos: %v", err)
+	}
+	sealed := map[string]sealedSecret{}
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		return nil, fmt.Errorf("arquivo de segredos inválido: %v", err)
+	}
+	return sealed, nil
+}
+
+func (s *Store) writeAll(sealed map[string]sealedSecret) error {
+	data, err := json.MarshalIndent(sealed, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("erro ao criar diretório de segredos: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("erro ao salvar segredos: %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// Get decifra os segredos pedidos pelo bot botID. Falha se algum deles não
+// existir ou não estiver liberado para o bot.
+func (s *Store) Get(botID string, names ...string) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(names))
+	if len(names) == 0 {
+		return values, nil
+	}
+
+	key, err := s.loadKey(false)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		entry, ok := sealed[name]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrSecretNotFound, name)
+		}
+		if !validation.MatchBots(entry.Bots, botID) {
+			return nil, fmt.Errorf("%w: %q não pode ser usado por %s", ErrSecretNotAllowed, name, botID)
+		}
+		raw, err := base64.StdEncoding.DecodeString(entry.Value)
+		if err != nil || len(raw) < aead.NonceSize() {
+			return nil, fmt.Errorf("segredo %q corrompido", name)
+		}
+		plain, err := aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], additionalData(name, entry.Bots))
+		if err != nil {
+			return nil, fmt.Errorf("não foi possível decifrar o segredo %q (chave errada?)", name)
+		}
+		values[name] = string(plain)
+	}
+	return values, nil
+}
+
+// Set cifra e grava value como o segredo name, substituindo o anterior.
+// bots restringe o segredo aos bots que casam com algum dos padrões
+// (path.Match, ex: "relatorio-*"); sem bots, qualquer bot pode usá-lo.
+func (s *Store) Set(name, value string, bots ...string) error {
+	for _, pattern := range bots {
+		if err := validation.BotPattern(pattern); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	key, err := s.loadKey(true)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	sealed[name] = sealedSecret{
+		Value: base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(value), additionalData(name, bots))),
+		Bots:  bots,
+	}
+	return s.writeAll(sealed)
+}
+
+func (s *Store) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := s.readAll()
+	if err != nil {
+		return err
+	}
+	if _, ok := sealed[name]; !ok {
+		return fmt.Errorf("%w: %q", ErrSecretNotFound, name)
+	}
+	delete(sealed, name)
+	return s.writeAll(sealed)
+}
+
+// List devolve os segredos cadastrados, sem os valores, ordenados por nome.
+func (s *Store) List() ([]Info, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sealed, err := s.readAll()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Info, 0, len(sealed))
+	for name, entry := range sealed {
+		list = append(list, Info{Name: name, Bots: entry.Bots})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
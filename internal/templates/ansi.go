@@ -0,0 +1,189 @@
+package templates
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ansiSegment é um trecho de uma linha de log com o estilo dos códigos ANSI
+// que o precedem, já traduzido em classes do Tailwind.
+type ansiSegment struct {
+	text  string
+	class string
+}
+
+// ansiStyle é o estado acumulado pelos códigos SGR (ESC[...m).
+type ansiStyle struct {
+	fg, bg    string
+	bold      bool
+	dim       bool
+	italic    bool
+	underline bool
+}
+
+func (s ansiStyle) class() string {
+	var classes []string
+	for _, c := range []struct {
+		on    bool
+		class string
+	}{
+		{s.fg != "", s.fg},
+		{s.bg != "", s.bg},
+		{s.bold, "font-bold"},
+		{s.dim, "opacity-70"},
+		{s.italic, "italic"},
+		{s.underline, "underline"},
+	} {
+		if c.on {
+			classes = append(classes, c.class)
+		}
+	}
+	return strings.Join(classes, " ")
+}
+
+// As 8 cores básicas (30-37/40-47) e as versões claras (90-97/100-107), na
+// ordem preto, vermelho, verde, amarelo, azul, magenta, ciano e branco.
+var (
+	ansiFg = [16]string{
+		"text-gray-500", "text-red-400", "text-green-400", "text-yellow-300",
+		"text-blue-400", "text-fuchsia-400", "text-cyan-400", "text-gray-200",
+		"text-gray-400", "text-red-300", "text-green-300", "text-yellow-200",
+		"text-blue-300", "text-fuchsia-300", "text-cyan-300", "text-white",
+	}
+	ansiBg = [16]string{
+		"bg-black", "bg-red-800", "bg-green-800", "bg-yellow-700",
+		"bg-blue-800", "bg-fuchsia-800", "bg-cyan-800", "bg-gray-300",
+		"bg-gray-700", "bg-red-600", "bg-green-600", "bg-yellow-500",
+		"bg-blue-600", "bg-fuchsia-600", "bg-cyan-600", "bg-white",
+	}
+)
+
+// ansiSegments separa line nos trechos de cada estilo. Só cores, negrito,
+// esmaecido, itálico e sublinhado são mantidos; as demais sequências de
+// escape (movimento de cursor, limpar linha, títulos OSC) e caracteres de
+// controle são descartados. O texto dos trechos ainda precisa ser escapado.
+func ansiSegments(line string) []ansiSegment {
+	var segments []ansiSegment
+	var style ansiStyle
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			segments = append(segments, ansiSegment{text: text.String(), class: style.class()})
+			text.Reset()
+		}
+	}
+
+scan:
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == 0x1b && i+1 < len(line) && line[i+1] == '[':
+			// CSI: parâmetros 0x30-0x3f, intermediários 0x20-0x2f e um
+			// byte final 0x40-0x7e.
+			end := i + 2
+			for end < len(line) && (line[end] < 0x40 || line[end] > 0x7e) {
+				end++
+			}
+			if end == len(line) {
+				break scan // sequência cortada no fim da linha
+			}
+			if line[end] == 'm' {
+				flush()
+				style.apply(line[i+2 : end])
+			}
+			i = end
+		case c == 0x1b && i+1 < len(line) && line[i+1] == ']':
+			// OSC: termina em BEL ou em ESC \.
+			end := i + 2
+			for end < len(line) && line[end] != 0x07 && !(line[end] == 0x1b && end+1 < len(line) && line[end+1] == '\\') {
+				end++
+			}
+			if end < len(line) && line[end] == 0x1b {
+				end++
+			}
+			i = end
+		case c == 0x1b:
+			i++ // escape de dois bytes, ex: ESC 7
+		case c == '\t' || c >= 0x20 && c != 0x7f:
+			text.WriteByte(c)
+		}
+	}
+	flush()
+	return segments
+}
+
+// apply interpreta os parâmetros de um código SGR, ex: "1;31".
+func (s *ansiStyle) apply(params string) {
+	codes := strings.FieldsFunc(params, func(r rune) bool { return r == ';' || r == ':' })
+	if len(codes) == 0 {
+		*s = ansiStyle{}
+		return
+	}
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			continue
+		}
+		switch {
+		case code == 0:
+			*s = ansiStyle{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.dim = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 22:
+			s.bold, s.dim = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code >= 30 && code <= 37:
+			s.fg = ansiFg[code-30]
+		case code >= 90 && code <= 97:
+			s.fg = ansiFg[code-90+8]
+		case code == 39:
+			s.fg = ""
+		case code >= 40 && code <= 47:
+			s.bg = ansiBg[code-40]
+		case code >= 100 && code <= 107:
+			s.bg = ansiBg[code-100+8]
+		case code == 49:
+			s.bg = ""
+		case code == 38 || code == 48:
+			// 38;5;n (256 cores) só é mantido para as 16 cores básicas;
+			// 38;2;r;g;b (24 bits) é ignorado.
+			color, skip := extendedColor(codes[i+1:])
+			i += skip
+			if color >= 0 && code == 38 {
+				s.fg = ansiFg[color]
+			} else if color >= 0 {
+				s.bg = ansiBg[color]
+			}
+		}
+	}
+}
+
+// extendedColor lê os argumentos de 38/48 e devolve o índice da cor básica
+// (ou -1) e quantos argumentos consumiu.
+func extendedColor(args []string) (int, int) {
+	if len(args) == 0 {
+		return -1, 0
+	}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return -1, len(args)
+		}
+		if n, err := strconv.Atoi(args[1]); err == nil && n >= 0 && n < 16 {
+			return n, 2
+		}
+		return -1, 2
+	case "2":
+		return -1, min(4, len(args))
+	}
+	return -1, 1
+}
@@ -0,0 +1,77 @@
+package templates
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestANSISegments(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []ansiSegment
+	}{
+		{"sem escape", "texto simples", []ansiSegment{{"texto simples", ""}}},
+		{"linha vazia", "", nil},
+		{"cor e reset", "\x1b[31merro\x1b[0m fim", []ansiSegment{{"erro", "text-red-400"}, {" fim", ""}}},
+		{"reset sem parâmetros", "\x1b[32mok\x1b[m fim", []ansiSegment{{"ok", "text-green-400"}, {" fim", ""}}},
+		{"negrito e cor clara", "\x1b[1;91mfalha", []ansiSegment{{"falha", "text-red-300 font-bold"}}},
+		{"fundo", "\x1b[37;41maviso", []ansiSegment{{"aviso", "text-gray-200 bg-red-800"}}},
+		{"fundo claro", "\x1b[104mx", []ansiSegment{{"x", "bg-blue-600"}}},
+		{
+			"ordem das classes", "\x1b[4;3;2;1;42;36mx",
+			[]ansiSegment{{"x", "text-cyan-400 bg-green-800 font-bold opacity-70 italic underline"}},
+		},
+		{
+			"desligar atributos", "\x1b[1;3;4;31;41ma\x1b[22;23;24;39;49mb",
+			[]ansiSegment{{"a", "text-red-400 bg-red-800 font-bold italic underline"}, {"b", ""}},
+		},
+		{"códigos seguidos sem texto", "\x1b[31m\x1b[1mx", []ansiSegment{{"x", "text-red-400 font-bold"}}},
+		{"256 cores básicas", "\x1b[38;5;2mx\x1b[48;5;9my", []ansiSegment{{"x", "text-green-400"}, {"y", "text-green-400 bg-red-600"}}},
+		{"256 cores fora das básicas", "\x1b[38;5;200;1mx", []ansiSegment{{"x", "font-bold"}}},
+		{"24 bits é ignorado", "\x1b[38;2;10;20;30;4mx", []ansiSegment{{"x", "underline"}}},
+		{"separador dois-pontos", "\x1b[38:5:1mx", []ansiSegment{{"x", "text-red-400"}}},
+		{"38 sem argumentos", "\x1b[38mx", []ansiSegment{{"x", ""}}},
+		{"outras sequências CSI", "a\x1b[2K\x1b[1Gb", []ansiSegment{{"ab", ""}}},
+		{"título OSC com BEL", "\x1b]0;título\x07texto", []ansiSegment{{"texto", ""}}},
+		{"título OSC com ESC \\", "\x1b]0;título\x1b\\texto", []ansiSegment{{"texto", ""}}},
+		{"escape de dois bytes", "a\x1b7b", []ansiSegment{{"ab", ""}}},
+		{"caracteres de controle", "a\x00b\x7fc\td\x08", []ansiSegment{{"abc\td", ""}}},
+		{"CSI cortado no fim", "texto\x1b[31", []ansiSegment{{"texto", ""}}},
+		{"ESC no fim", "texto\x1b", []ansiSegment{{"texto", ""}}},
+		{"UTF-8 preservado", "\x1b[33mação ✓", []ansiSegment{{"ação ✓", "text-yellow-300"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansiSegments(tt.line)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ansiSegments(%q) = %+v, esperado %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtendedColor(t *testing.T) {
+	tests := []struct {
+		args      []string
+		wantColor int
+		wantSkip  int
+	}{
+		{nil, -1, 0},
+		{[]string{"5", "3"}, 3, 2},
+		{[]string{"5", "15", "1"}, 15, 2},
+		{[]string{"5", "16"}, -1, 2},
+		{[]string{"5", "x"}, -1, 2},
+		{[]string{"5"}, -1, 1},
+		{[]string{"2", "1", "2", "3", "1"}, -1, 4},
+		{[]string{"2", "1"}, -1, 2},
+		{[]string{"9"}, -1, 1},
+	}
+	for _, tt := range tests {
+		color, skip := extendedColor(tt.args)
+		if color != tt.wantColor || skip != tt.wantSkip {
+			t.Errorf("extendedColor(%q) = (%d, %d), esperado (%d, %d)", tt.args, color, skip, tt.wantColor, tt.wantSkip)
+		}
+	}
+}
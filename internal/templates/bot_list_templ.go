@@ -29,7 +29,7 @@ func DeployForm() templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"max-w-md bg-gray-800 p-6 rounded-lg shadow-lg mx-auto mt-10\"><h2 class=\"text-lg mb-4 font-semibold\">Iniciar Robô</h2><form id=\"deploy-form\" class=\"space-y-4\"><div><label class=\"block text-sm text-gray-400\">Bot ID</label> <input name=\"bot_id\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"ex: rpa-01\"></div><div><label class=\"block text-sm text-gray-400\">Git Repo</label> <input name=\"git_repo\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"https://github.com/...\"></div><div><label class=\"block text-sm text-gray-400\">Versão</label> <input name=\"version\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"1.0.0\"></div><button type=\"submit\" class=\"w-full bg-blue-600 hover:bg-blue-500 py-2 rounded font-bold transition\">rodar o bot 🚀</button></form><div id=\"log-container\" class=\"mt-6 p-4 bg-black rounded text-green-500 font-mono text-sm h-64 overflow-y-auto\">Aguardando comando...</div></div><script>\r\n\t\tdocument.getElementById('deploy-form').addEventListener('submit', async (e) => {\r\n\t\t\te.preventDefault();\r\n\t\t\t\r\n\t\t\tconst form = e.target;\r\n\t\t\tconst logContainer = document.getElementById('log-container');\r\n\t\t\tlogContainer.innerHTML = '<div class=\"text-yellow-400\">Iniciando...</div>';\r\n\t\t\t\r\n\t\t\tconst data = {\r\n\t\t\t\tbot_id: form.bot_id.value,\r\n\t\t\t\tgit_repo: form.git_repo.value,\r\n\t\t\t\tversion: form.version.value\r\n\t\t\t};\r\n\r\n\t\t\ttry {\r\n\t\t\t\tconst response = await fetch('/bots/run', {\r\n\t\t\t\t\tmethod: 'POST',\r\n\t\t\t\t\theaders: { 'Content-Type': 'application/json' },\r\n\t\t\t\t\tbody: JSON.stringify(data)\r\n\t\t\t\t});\r\n\r\n\t\t\t\tconst reader = response.body.getReader();\r\n\t\t\t\tconst decoder = new TextDecoder();\r\n\r\n\t\t\t\twhile (true) {\r\n\t\t\t\t\tconst { done, value } = await reader.read();\r\n\t\t\t\t\tif (done) break;\r\n\t\t\t\t\t\r\n\t\t\t\t\tconst text = decoder.decode(value, { stream: true });\r\n\t\t\t\t\tlogContainer.innerHTML += text;\r\n\t\t\t\t\tlogContainer.scrollTop = logContainer.scrollHeight;\r\n\t\t\t\t}\r\n\t\t\t} catch (err) {\r\n\t\t\t\tlogContainer.innerHTML += `<div class=\"text-red-400\">[ERROR] ${err.message}</div>`;\r\n\t\t\t}\r\n\t\t});\r\n\t</script>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<div class=\"max-w-md bg-gray-800 p-6 rounded-lg shadow-lg mx-auto mt-10\"><h2 class=\"text-lg mb-4 font-semibold\">Iniciar Robô</h2><form id=\"deploy-form\" class=\"space-y-4\"><div><label class=\"block text-sm text-gray-400\">Bot ID</label> <input name=\"bot_id\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"ex: rpa-01\"></div><div><label class=\"block text-sm text-gray-400\">Git Repo</label> <input name=\"git_repo\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"https://github.com/...\"></div><div><label class=\"block text-sm text-gray-400\">Versão</label> <input name=\"version\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"1.0.0\"></div><div><label class=\"block text-sm text-gray-400\">Credencial (opcional)</label> <input name=\"credential\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"nome cadastrado no agente\"></div><div><label class=\"block text-sm text-gray-400\">Variáveis de ambiente (CHAVE=valor, uma por linha)</label> <textarea name=\"env\" rows=\"2\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1 font-mono text-sm\" placeholder=\"AMBIENTE=producao\"></textarea></div><div><label class=\"block text-sm text-gray-400\">Segredos (separados por vírgula)</label> <input name=\"secret_refs\" type=\"text\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"API_KEY, DB_PASSWORD=senha_banco\"></div><div><label class=\"block text-sm text-gray-400\">Tempo limite em segundos (opcional)</label> <input name=\"timeout_seconds\" type=\"number\" min=\"0\" class=\"w-full bg-gray-700 border-none rounded p-2 mt-1\" placeholder=\"padrão do bot.yaml ou do agente\"></div><label class=\"flex items-center gap-2 text-sm text-gray-400\"><input name=\"force_reclone\" type=\"checkbox\" class=\"bg-gray-700 rounded\"> Forçar novo clone</label> <button type=\"submit\" class=\"w-full bg-blue-600 hover:bg-blue-500 py-2 rounded font-bold transition\">rodar o bot 🚀</button></form><button id=\"stop-button\" type=\"button\" class=\"hidden w-full mt-4 bg-red-600 hover:bg-red-500 py-2 rounded font-bold transition\">parar o bot ⏹</button><div id=\"log-container\" class=\"mt-6 p-4 bg-black rounded text-green-500 font-mono text-sm h-64 overflow-y-auto\">Aguardando comando...</div></div><script>\n\t\tconst stopButton = document.getElementById('stop-button');\n\t\tconst logContainer = document.getElementById('log-container');\n\t\tlet currentJobId = null;\n\n\t\t// Mensagens montadas aqui vão como texto: podem trazer o id do job da\n\t\t// URL ou erros com conteúdo vindo do agente.\n\t\tfunction showMessage(className, text) {\n\t\t\tconst div = document.createElement('div');\n\t\t\tdiv.className = className;\n\t\t\tdiv.textContent = text;\n\t\t\tlogContainer.appendChild(div);\n\t\t}\n\n\t\tstopButton.addEventListener('click', async () => {\n\t\t\tif (!currentJobId) return;\n\t\t\tstopButton.disabled = true;\n\t\t\tconst response = await fetch(`/jobs/${currentJobId}/cancel`, { method: 'POST' });\n\t\t\tif (!response.ok) {\n\t\t\t\tshowMessage('text-red-400', `[ERROR] ${await response.text()}`);\n\t\t\t\tstopButton.disabled = false;\n\t\t\t}\n\t\t});\n\n\t\t// Converte as linhas CHAVE=valor do formulário no mapa env do DeployRequest.\n\t\tfunction parseEnv(text) {\n\t\t\tconst env = {};\n\t\t\tfor (const line of text.split('\\n')) {\n\t\t\t\tconst i = line.indexOf('=');\n\t\t\t\tif (i > 0) env[line.slice(0, i).trim()] = line.slice(i + 1);\n\t\t\t}\n\t\t\treturn env;\n\t\t}\n\n\t\t// Consome o stream HTML de logs; o id do job fica salvo para que um\n\t\t// refresh da página volte a acompanhar a mesma execução. Cada fragmento\n\t\t// do servidor termina em uma quebra de linha, e só fragmentos completos\n\t\t// são inseridos, para uma linha longa dividida entre dois pedaços não\n\t\t// ser interpretada pela metade.\n\t\tasync function followLogs(request) {\n\t\t\ttry {\n\t\t\t\tconst response = await request;\n\t\t\t\tconst reader = response.body.getReader();\n\t\t\t\tconst decoder = new TextDecoder();\n\t\t\t\tlet pending = '';\n\n\t\t\t\twhile (true) {\n\t\t\t\t\tconst { done, value } = await reader.read();\n\t\t\t\t\tpending += done ? decoder.decode() : decoder.decode(value, { stream: true });\n\t\t\t\t\tconst cut = done ? pending.length : pending.lastIndexOf('\\n') + 1;\n\t\t\t\t\tif (cut > 0) {\n\t\t\t\t\t\tlogContainer.insertAdjacentHTML('beforeend', pending.slice(0, cut));\n\t\t\t\t\t\tpending = pending.slice(cut);\n\t\t\t\t\t\tlogContainer.scrollTop = logContainer.scrollHeight;\n\t\t\t\t\t}\n\t\t\t\t\tif (done) break;\n\n\t\t\t\t\tif (!currentJobId) {\n\t\t\t\t\t\tconst marker = logContainer.querySelector('[data-job-id]');\n\t\t\t\t\t\tif (marker) {\n\t\t\t\t\t\t\tcurrentJobId = marker.dataset.jobId;\n\t\t\t\t\t\t\tlocalStorage.setItem('currentJobId', currentJobId);\n\t\t\t\t\t\t\tstopButton.disabled = false;\n\t\t\t\t\t\t\tstopButton.classList.remove('hidden');\n\t\t\t\t\t\t}\n\t\t\t\t\t}\n\t\t\t\t}\n\t\t\t\tlocalStorage.removeItem('currentJobId');\n\t\t\t} catch (err) {\n\t\t\t\tshowMessage('text-red-400', `[ERROR] ${err.message}`);\n\t\t\t} finally {\n\t\t\t\tstopButton.classList.add('hidden');\n\t\t\t}\n\t\t}\n\n\t\tdocument.getElementById('deploy-form').addEventListener('submit', async (e) => {\n\t\t\te.preventDefault();\n\t\t\t\n\t\t\tconst form = e.target;\n\t\t\tlogContainer.innerHTML = '<div class=\"text-yellow-400\">Iniciando...</div>';\n\t\t\tcurrentJobId = null;\n\t\t\t\n\t\t\tconst data = {\n\t\t\t\tbot_id: form.bot_id.value,\n\t\t\t\tgit_repo: form.git_repo.value,\n\t\t\t\tversion: form.version.value,\n\t\t\t\tforce_reclone: form.force_reclone.checked,\n\t\t\t\tcredential: form.credential.value,\n\t\t\t\tenv: parseEnv(form.env.value),\n\t\t\t\tsecret_refs: form.secret_refs.value.split(',').map(s => s.trim()).filter(s => s),\n\t\t\t\ttimeout_seconds: Number(form.timeout_seconds.value) || 0\n\t\t\t};\n\n\t\t\tawait followLogs(fetch('/bots/run', {\n\t\t\t\tmethod: 'POST',\n\t\t\t\theaders: { 'Content-Type': 'application/json' },\n\t\t\t\tbody: JSON.stringify(data)\n\t\t\t}));\n\t\t});\n\n\t\tconst attachJobId = new URLSearchParams(window.location.search).get('job') || localStorage.getItem('currentJobId');\n\t\tif (attachJobId) {\n\t\t\tlogContainer.replaceChildren();\n\t\t\tshowMessage('text-yellow-400', `Reconectando ao job ${attachJobId}...`);\n\t\t\tfollowLogs(fetch(`/jobs/${encodeURIComponent(attachJobId)}/attach`));\n\t\t}\n\t</script>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"orchestrator/pb"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func jobStateLabel(state pb.JobState) string {
+	switch state {
+	case pb.JobState_JOB_STATE_QUEUED:
+		return "Na fila"
+	case pb.JobState_JOB_STATE_CLONING:
+		return "Clonando"
+	case pb.JobState_JOB_STATE_INSTALLING:
+		return "Instalando"
+	case pb.JobState_JOB_STATE_RUNNING:
+		return "Executando"
+	case pb.JobState_JOB_STATE_SUCCEEDED:
+		return "Sucesso"
+	case pb.JobState_JOB_STATE_FAILED:
+		return "Falhou"
+	case pb.JobState_JOB_STATE_CANCELLED:
+		return "Cancelado"
+	}
+	return "Desconhecido"
+}
+
+func jobStateClass(state pb.JobState) string {
+	switch state {
+	case pb.JobState_JOB_STATE_SUCCEEDED:
+		return "text-green-400"
+	case pb.JobState_JOB_STATE_FAILED:
+		return "text-red-400"
+	case pb.JobState_JOB_STATE_CANCELLED:
+		return "text-yellow-400"
+	case pb.JobState_JOB_STATE_QUEUED:
+		return "text-purple-300"
+	}
+	return "text-blue-300"
+}
+
+func formatTimestamp(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return "-"
+	}
+	return ts.AsTime().Local().Format("02/01/2006 15:04:05")
+}
+
+func jobDuration(job *pb.Job) string {
+	if job.StartedAt == nil {
+		return "-"
+	}
+	end := time.Now()
+	if job.FinishedAt != nil {
+		end = job.FinishedAt.AsTime()
+	}
+	return end.Sub(job.StartedAt.AsTime()).Round(time.Second).String()
+}
+
+func logStatusLabel(status pb.LogStatus) string {
+	return strings.TrimPrefix(status.String(), "LOG_STATUS_")
+}
+
+// logLineClass é a cor de uma linha de log pelo status e, nas linhas de
+// saída, pelo stream de origem.
+func logLineClass(msg *pb.LogResponse) string {
+	switch msg.Status {
+	case pb.LogStatus_LOG_STATUS_SUCCESS:
+		return "text-green-400"
+	case pb.LogStatus_LOG_STATUS_ERROR:
+		return "text-red-400"
+	case pb.LogStatus_LOG_STATUS_CANCELLED:
+		return "text-yellow-400"
+	case pb.LogStatus_LOG_STATUS_QUEUED:
+		return "text-purple-300"
+	case pb.LogStatus_LOG_STATUS_INFO:
+		switch msg.Source {
+		case pb.LogSource_LOG_SOURCE_STDOUT:
+			return "text-gray-200"
+		case pb.LogSource_LOG_SOURCE_STDERR:
+			return "text-orange-300"
+		}
+		return "text-blue-300"
+	}
+	return "text-gray-300"
+}
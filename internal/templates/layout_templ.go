@@ -29,7 +29,7 @@ func Layout(contents templ.Component) templ.Component {
 			templ_7745c5c3_Var1 = templ.NopComponent
 		}
 		ctx = templ.ClearChildren(ctx)
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>Common Orchestrator</title><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script src=\"https://cdn.tailwindcss.com\"></script></head><body class=\"bg-gray-900 text-white font-sans\"><nav class=\"p-4 border-b border-gray-800 flex justify-center\"><h1 class=\"text-xl font-bold text-blue-400\">Common Agent Manager</h1></nav><main class=\"p-8\">")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 1, "<!doctype html><html lang=\"en\"><head><meta charset=\"UTF-8\"><meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\"><title>Common Orchestrator</title><script src=\"https://unpkg.com/htmx.org@1.9.10\"></script><script src=\"https://cdn.tailwindcss.com\"></script></head><body class=\"bg-gray-900 text-white font-sans\"><nav class=\"p-4 border-b border-gray-800 flex justify-center items-center gap-8\"><h1 class=\"text-xl font-bold text-blue-400\">Common Agent Manager</h1><a href=\"/\" class=\"text-sm text-gray-300 hover:text-white\">Executar</a> <a href=\"/jobs\" class=\"text-sm text-gray-300 hover:text-white\">Histórico</a></nav><main class=\"p-8\">")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -0,0 +1,287 @@
+// Package validation concentra as regras de entrada compartilhadas pelo
+// cliente HTTP e pelo agente. O agente sempre valida de novo: a checagem do
+// cliente só serve para responder mais cedo.
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"orchestrator/pb"
+	"path"
+	"regexp"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// bot_id, version e nomes de credencial viram nomes de diretório; sem
+	// barras e começando por letra ou dígito, não há como escapar de ./bots.
+	identifierPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	envNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// scpLikePattern casa o formato curto do ssh, ex: git@github.com:org/repo.git.
+	scpLikePattern = regexp.MustCompile(`^(?:[A-Za-z0-9._-]+@)?([A-Za-z0-9.-]+):([^/].*|/.+)$`)
+)
+
+const (
+	maxBotIDLength   = 64
+	maxVersionLength = 128
+	// maxTimeoutSeconds é uma semana.
+	maxTimeoutSeconds = 7 * 24 * 60 * 60
+)
+
+// FieldViolation é um problema em um campo da requisição.
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error reúne todas as violações de uma requisição. Implementa GRPCStatus,
+// então pode ser devolvido direto por um handler gRPC.
+type Error struct {
+	Violations []FieldViolation
+}
+
+func (e *Error) add(field, format string, args ...any) {
+	e.Violations = append(e.Violations, FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+func (e *Error) orNil() error {
+	if len(e.Violations) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.Field + ": " + v.Description
+	}
+	return "requisição inválida: " + strings.Join(parts, "; ")
+}
+
+// GRPCStatus devolve InvalidArgument com um errdetails.BadRequest listando
+// os campos.
+func (e *Error) GRPCStatus() *status.Status {
+	st := status.New(codes.InvalidArgument, e.Error())
+	details := &errdetails.BadRequest{}
+	for _, v := range e.Violations {
+		details.FieldViolations = append(details.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	if withDetails, err := st.WithDetails(details); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// Violations extrai as violações de um erro de validação local ou de um
+// status gRPC InvalidArgument vindo do agente.
+func Violations(err error) []FieldViolation {
+	var verr *Error
+	if errors.As(err, &verr) {
+		return verr.Violations
+	}
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.InvalidArgument {
+		return nil
+	}
+	var violations []FieldViolation
+	for _, detail := range st.Details() {
+		if br, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				violations = append(violations, FieldViolation{Field: v.Field, Description: v.Description})
+			}
+		}
+	}
+	return violations
+}
+
+// Policy define de onde os bots podem ser clonados.
+type Policy struct {
+	// Schemes permitidos: "https", "ssh", "http" ou "file" (caminhos locais).
+	Schemes map[string]bool
+	// Hosts permitidos. "*" libera qualquer host e "*.empresa.com" libera os
+	// subdomínios.
+	Hosts []string
+}
+
+func NewPolicy(schemes, hosts []string) Policy {
+	p := Policy{Schemes: make(map[string]bool)}
+	for _, scheme := range schemes {
+		p.Schemes[strings.ToLower(scheme)] = true
+	}
+	for _, host := range hosts {
+		p.Hosts = append(p.Hosts, strings.ToLower(host))
+	}
+	return p
+}
+
+func (p Policy) hostAllowed(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range p.Hosts {
+		switch {
+		case pattern == "*", pattern == host:
+			return true
+		case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]):
+			return true
+		}
+	}
+	return false
+}
+
+// DeployRequest valida um DeployRequest. prefix é acrescentado ao nome dos
+// campos quando a requisição vem aninhada (ex: "bot." em CreateSchedule).
+func (p Policy) DeployRequest(req *pb.DeployRequest, prefix string) error {
+	e := &Error{}
+	p.deployRequest(e, req, prefix)
+	return e.orNil()
+}
+
+func (p Policy) deployRequest(e *Error, req *pb.DeployRequest, prefix string) {
+	checkIdentifier(e, prefix+"bot_id", req.GetBotId(), maxBotIDLength)
+	checkIdentifier(e, prefix+"version", req.GetVersion(), maxVersionLength)
+	if strings.Contains(req.GetVersion(), "..") || strings.HasSuffix(req.GetVersion(), ".lock") {
+		e.add(prefix+"version", "não é um nome de ref git válido")
+	}
+	if req.GetCredential() != "" {
+		checkIdentifier(e, prefix+"credential", req.GetCredential(), maxBotIDLength)
+	}
+	p.checkRepo(e, prefix+"git_repo", req.GetGitRepo())
+
+	for name := range req.GetEnv() {
+		if !envNamePattern.MatchString(name) {
+			e.add(fmt.Sprintf("%senv[%s]", prefix, name), "nome de variável de ambiente inválido")
+		}
+	}
+	if req.GetTimeoutSeconds() > maxTimeoutSeconds {
+		e.add(prefix+"timeout_seconds", "deve ser no máximo %d (uma semana)", maxTimeoutSeconds)
+	}
+	for i, ref := range req.GetSecretRefs() {
+		if _, _, err := ParseSecretRef(ref); err != nil {
+			e.add(fmt.Sprintf("%ssecret_refs[%d]", prefix, i), "use NOME ou VAR=nome")
+		}
+	}
+}
+
+// BotVersion valida o par bot_id/version usado para localizar uma versão em
+// disco.
+func BotVersion(botID, version string) error {
+	e := &Error{}
+	checkIdentifier(e, "bot_id", botID, maxBotIDLength)
+	checkIdentifier(e, "version", version, maxVersionLength)
+	return e.orNil()
+}
+
+func checkIdentifier(e *Error, field, value string, maxLength int) {
+	switch {
+	case value == "":
+		e.add(field, "é obrigatório")
+	case len(value) > maxLength:
+		e.add(field, "deve ter no máximo %d caracteres", maxLength)
+	case !identifierPattern.MatchString(value):
+		e.add(field, "use apenas letras, dígitos, '.', '_' e '-', começando por letra ou dígito")
+	}
+}
+
+func (p Policy) checkRepo(e *Error, field, repo string) {
+	if repo == "" {
+		e.add(field, "é obrigatório")
+		return
+	}
+	if strings.HasPrefix(repo, "-") || strings.ContainsAny(repo, " \t\r\n") {
+		e.add(field, "URL inválida")
+		return
+	}
+	// "transporte::endereço" aciona remote helpers como o ext::, que executa
+	// comandos arbitrários.
+	if strings.Contains(repo, "::") {
+		e.add(field, "transportes do tipo <transporte>::<endereço> não são permitidos")
+		return
+	}
+
+	scheme, host, err := parseRepo(repo)
+	if err != nil {
+		e.add(field, "%v", err)
+		return
+	}
+	if !p.Schemes[scheme] {
+		e.add(field, "esquema %q não permitido", scheme)
+		return
+	}
+	if scheme != "file" && !p.hostAllowed(host) {
+		e.add(field, "host %q não está na lista de hosts permitidos", host)
+	}
+}
+
+// parseRepo devolve o esquema e o host de um endereço aceito pelo git: URL,
+// formato curto do ssh ou caminho absoluto local.
+func parseRepo(repo string) (scheme, host string, err error) {
+	if strings.HasPrefix(repo, "/") {
+		return "file", "", nil
+	}
+	if !strings.Contains(repo, "://") {
+		if m := scpLikePattern.FindStringSubmatch(repo); m != nil {
+			return "ssh", m[1], nil
+		}
+		return "", "", fmt.Errorf("URL inválida")
+	}
+
+	u, err := url.Parse(repo)
+	if err != nil {
+		return "", "", fmt.Errorf("URL inválida")
+	}
+	scheme = strings.ToLower(u.Scheme)
+	if scheme == "git+ssh" || scheme == "ssh+git" {
+		scheme = "ssh"
+	}
+	if scheme != "file" && u.Hostname() == "" {
+		return "", "", fmt.Errorf("URL sem host")
+	}
+	return scheme, u.Hostname(), nil
+}
+
+// IsEnvName informa se name é um nome de variável de ambiente aceito.
+func IsEnvName(name string) bool {
+	return envNamePattern.MatchString(name)
+}
+
+// ParseSecretRef interpreta uma entrada de secret_refs: "NOME" ou "VAR=nome".
+func ParseSecretRef(ref string) (envName, secretName string, err error) {
+	envName, secretName, ok := strings.Cut(ref, "=")
+	if !ok {
+		secretName = envName
+	}
+	if !envNamePattern.MatchString(envName) || secretName == "" {
+		return "", "", fmt.Errorf("secret_ref inválido %q", ref)
+	}
+	return envName, secretName, nil
+}
+
+// BotPattern valida um padrão de bots (path.Match), ex: "relatorio-*".
+func BotPattern(pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil || pattern == "" {
+		return fmt.Errorf("padrão de bot inválido %q", pattern)
+	}
+	return nil
+}
+
+// MatchBots informa se botID casa com algum dos padrões; uma lista vazia
+// libera todos os bots.
+func MatchBots(patterns []string, botID string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, botID); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package validation
+
+import (
+	"orchestrator/pb"
+	"strings"
+	"testing"
+)
+
+func validRequest() *pb.DeployRequest {
+	return &pb.DeployRequest{
+		BotId:   "relatorio-diario",
+		GitRepo: "https://github.com/empresa/relatorio.git",
+		Version: "main",
+	}
+}
+
+func fields(err error) []string {
+	var names []string
+	for _, v := range Violations(err) {
+		names = append(names, v.Field)
+	}
+	return names
+}
+
+func TestDeployRequest(t *testing.T) {
+	policy := NewPolicy([]string{"https", "SSH"}, []string{"github.com", "*.empresa.com"})
+
+	tests := []struct {
+		name   string
+		modify func(req *pb.DeployRequest)
+		want   []string // campos com violação; vazio quando a requisição é válida
+	}{
+		{"válida", func(req *pb.DeployRequest) {}, nil},
+		{"ssh curto", func(req *pb.DeployRequest) { req.GitRepo = "git@github.com:empresa/relatorio.git" }, nil},
+		{"ssh url", func(req *pb.DeployRequest) { req.GitRepo = "ssh://git@github.com/empresa/relatorio.git" }, nil},
+		{"subdomínio liberado", func(req *pb.DeployRequest) { req.GitRepo = "https://git.empresa.com/relatorio.git" }, nil},
+		{"domínio do curinga não é subdomínio", func(req *pb.DeployRequest) { req.GitRepo = "https://empresa.com/relatorio.git" }, []string{"git_repo"}},
+		{"host fora da lista", func(req *pb.DeployRequest) { req.GitRepo = "https://gitlab.com/empresa/relatorio.git" }, []string{"git_repo"}},
+		{"esquema não permitido", func(req *pb.DeployRequest) { req.GitRepo = "http://github.com/empresa/relatorio.git" }, []string{"git_repo"}},
+		{"caminho local sem file", func(req *pb.DeployRequest) { req.GitRepo = "/srv/repos/relatorio" }, []string{"git_repo"}},
+		{"remote helper", func(req *pb.DeployRequest) { req.GitRepo = "ext::sh -c touch% /tmp/x" }, []string{"git_repo"}},
+		{"opção do git", func(req *pb.DeployRequest) { req.GitRepo = "--upload-pack=touch /tmp/x" }, []string{"git_repo"}},
+		{"repo vazio", func(req *pb.DeployRequest) { req.GitRepo = "" }, []string{"git_repo"}},
+		{"bot_id com barra", func(req *pb.DeployRequest) { req.BotId = "../etc" }, []string{"bot_id"}},
+		{"bot_id longo", func(req *pb.DeployRequest) { req.BotId = strings.Repeat("a", maxBotIDLength+1) }, []string{"bot_id"}},
+		{"version com ..", func(req *pb.DeployRequest) { req.Version = "v1..v2" }, []string{"version"}},
+		{"version .lock", func(req *pb.DeployRequest) { req.Version = "main.lock" }, []string{"version"}},
+		{"credencial inválida", func(req *pb.DeployRequest) { req.Credential = "a/b" }, []string{"credential"}},
+		{"env inválido", func(req *pb.DeployRequest) { req.Env = map[string]string{"1X": "a"} }, []string{"env[1X]"}},
+		{"timeout longo", func(req *pb.DeployRequest) { req.TimeoutSeconds = maxTimeoutSeconds + 1 }, []string{"timeout_seconds"}},
+		{"secret_ref inválido", func(req *pb.DeployRequest) { req.SecretRefs = []string{"TOKEN", "=x"} }, []string{"secret_refs[1]"}},
+		{"várias violações", func(req *pb.DeployRequest) {
+			req.BotId = ""
+			req.Version = ""
+		}, []string{"bot_id", "version"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.modify(req)
+			err := policy.DeployRequest(req, "")
+			got := fields(err)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("violações = %v, esperado %v (erro: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestDeployRequestPrefix(t *testing.T) {
+	policy := NewPolicy([]string{"https"}, []string{"github.com"})
+	req := validRequest()
+	req.BotId = ""
+	got := fields(policy.DeployRequest(req, "bot."))
+	if len(got) != 1 || got[0] != "bot.bot_id" {
+		t.Fatalf("violações = %v, esperado [bot.bot_id]", got)
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		repo       string
+		wantScheme string
+		wantHost   string
+		wantErr    bool
+	}{
+		{repo: "https://github.com/org/repo.git", wantScheme: "https", wantHost: "github.com"},
+		{repo: "HTTPS://GitHub.com/org/repo.git", wantScheme: "https", wantHost: "GitHub.com"},
+		{repo: "git@github.com:org/repo.git", wantScheme: "ssh", wantHost: "github.com"},
+		{repo: "github.com:org/repo.git", wantScheme: "ssh", wantHost: "github.com"},
+		{repo: "git+ssh://git@github.com/org/repo.git", wantScheme: "ssh", wantHost: "github.com"},
+		{repo: "file:///srv/repos/bot", wantScheme: "file"},
+		{repo: "/srv/repos/bot", wantScheme: "file"},
+		{repo: "https:///org/repo.git", wantErr: true},
+		{repo: "repo.git", wantErr: true},
+	}
+	for _, tt := range tests {
+		scheme, host, err := parseRepo(tt.repo)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRepo(%q) erro = %v, esperado erro: %v", tt.repo, err, tt.wantErr)
+			continue
+		}
+		if scheme != tt.wantScheme || host != tt.wantHost {
+			t.Errorf("parseRepo(%q) = (%q, %q), esperado (%q, %q)", tt.repo, scheme, host, tt.wantScheme, tt.wantHost)
+		}
+	}
+}
+
+func TestParseSecretRef(t *testing.T) {
+	tests := []struct {
+		ref               string
+		wantEnv, wantName string
+		wantErr           bool
+	}{
+		{ref: "API_TOKEN", wantEnv: "API_TOKEN", wantName: "API_TOKEN"},
+		{ref: "TOKEN=api-token", wantEnv: "TOKEN", wantName: "api-token"},
+		{ref: "TOKEN=", wantErr: true},
+		{ref: "1TOKEN", wantErr: true},
+		{ref: "=api-token", wantErr: true},
+		{ref: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		env, name, err := ParseSecretRef(tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSecretRef(%q) erro = %v, esperado erro: %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if env != tt.wantEnv || name != tt.wantName {
+			t.Errorf("ParseSecretRef(%q) = (%q, %q), esperado (%q, %q)", tt.ref, env, name, tt.wantEnv, tt.wantName)
+		}
+	}
+}
+
+// As violações precisam sobreviver à ida ao cliente como status gRPC.
+func TestViolationsFromStatus(t *testing.T) {
+	policy := NewPolicy([]string{"https"}, []string{"github.com"})
+	req := validRequest()
+	req.GitRepo = "http://github.com/org/repo.git"
+	err := policy.DeployRequest(req, "")
+	verr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("erro %T, esperado *Error", err)
+	}
+
+	got := Violations(verr.GRPCStatus().Err())
+	if len(got) != 1 || got[0] != verr.Violations[0] {
+		t.Fatalf("Violations(status) = %+v, esperado %+v", got, verr.Violations)
+	}
+	if Violations(nil) != nil {
+		t.Fatal("Violations(nil) deveria ser vazio")
+	}
+}
+
+func TestMatchBots(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		botID    string
+		want     bool
+	}{
+		{nil, "qualquer", true},
+		{[]string{"relatorio-*"}, "relatorio-diario", true},
+		{[]string{"relatorio-*"}, "cobranca", false},
+		{[]string{"cobranca", "relatorio-?"}, "relatorio-1", true},
+		{[]string{"cobranca", "relatorio-?"}, "relatorio-10", false},
+	}
+	for _, tt := range tests {
+		if got := MatchBots(tt.patterns, tt.botID); got != tt.want {
+			t.Errorf("MatchBots(%q, %q) = %v, esperado %v", tt.patterns, tt.botID, got, tt.want)
+		}
+	}
+	for _, pattern := range []string{"", "[a-"} {
+		if err := BotPattern(pattern); err == nil {
+			t.Errorf("BotPattern(%q) aceitou um padrão inválido", pattern)
+		}
+	}
+}
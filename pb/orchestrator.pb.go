@@ -9,6 +9,8 @@ package pb
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,13 +23,364 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// FailureReason classifica a falha de um job. Cada motivo corresponde a um
+// código gRPC distinto no status final de ExecuteDeploy e AttachLogs.
+type FailureReason int32
+
+const (
+	FailureReason_FAILURE_REASON_UNSPECIFIED    FailureReason = 0 // job concluído com sucesso
+	FailureReason_FAILURE_REASON_CANCELLED      FailureReason = 1 // CANCELLED
+	FailureReason_FAILURE_REASON_INVALID_CONFIG FailureReason = 2 // FAILED_PRECONDITION: credencial, segredo ou bot.yaml
+	FailureReason_FAILURE_REASON_CLONE          FailureReason = 3 // UNAVAILABLE
+	FailureReason_FAILURE_REASON_DEPENDENCIES   FailureReason = 4 // ABORTED
+	FailureReason_FAILURE_REASON_BOT_EXIT       FailureReason = 5 // UNKNOWN: o bot terminou com erro ou foi morto por um sinal
+	FailureReason_FAILURE_REASON_TIMEOUT        FailureReason = 6 // DEADLINE_EXCEEDED
+	FailureReason_FAILURE_REASON_RESOURCE_LIMIT FailureReason = 7 // RESOURCE_EXHAUSTED
+)
+
+// Enum value maps for FailureReason.
+var (
+	FailureReason_name = map[int32]string{
+		0: "FAILURE_REASON_UNSPECIFIED",
+		1: "FAILURE_REASON_CANCELLED",
+		2: "FAILURE_REASON_INVALID_CONFIG",
+		3: "FAILURE_REASON_CLONE",
+		4: "FAILURE_REASON_DEPENDENCIES",
+		5: "FAILURE_REASON_BOT_EXIT",
+		6: "FAILURE_REASON_TIMEOUT",
+		7: "FAILURE_REASON_RESOURCE_LIMIT",
+	}
+	FailureReason_value = map[string]int32{
+		"FAILURE_REASON_UNSPECIFIED":    0,
+		"FAILURE_REASON_CANCELLED":      1,
+		"FAILURE_REASON_INVALID_CONFIG": 2,
+		"FAILURE_REASON_CLONE":          3,
+		"FAILURE_REASON_DEPENDENCIES":   4,
+		"FAILURE_REASON_BOT_EXIT":       5,
+		"FAILURE_REASON_TIMEOUT":        6,
+		"FAILURE_REASON_RESOURCE_LIMIT": 7,
+	}
+)
+
+func (x FailureReason) Enum() *FailureReason {
+	p := new(FailureReason)
+	*p = x
+	return p
+}
+
+func (x FailureReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FailureReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[0].Descriptor()
+}
+
+func (FailureReason) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[0]
+}
+
+func (x FailureReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FailureReason.Descriptor instead.
+func (FailureReason) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{0}
+}
+
+type LogStatus int32
+
+const (
+	LogStatus_LOG_STATUS_UNSPECIFIED LogStatus = 0
+	LogStatus_LOG_STATUS_INFO        LogStatus = 1
+	LogStatus_LOG_STATUS_SUCCESS     LogStatus = 2
+	LogStatus_LOG_STATUS_ERROR       LogStatus = 3
+	LogStatus_LOG_STATUS_CANCELLED   LogStatus = 4
+	LogStatus_LOG_STATUS_QUEUED      LogStatus = 5 // o job aguarda o lock da versão ou uma vaga no agente
+)
+
+// Enum value maps for LogStatus.
+var (
+	LogStatus_name = map[int32]string{
+		0: "LOG_STATUS_UNSPECIFIED",
+		1: "LOG_STATUS_INFO",
+		2: "LOG_STATUS_SUCCESS",
+		3: "LOG_STATUS_ERROR",
+		4: "LOG_STATUS_CANCELLED",
+		5: "LOG_STATUS_QUEUED",
+	}
+	LogStatus_value = map[string]int32{
+		"LOG_STATUS_UNSPECIFIED": 0,
+		"LOG_STATUS_INFO":        1,
+		"LOG_STATUS_SUCCESS":     2,
+		"LOG_STATUS_ERROR":       3,
+		"LOG_STATUS_CANCELLED":   4,
+		"LOG_STATUS_QUEUED":      5,
+	}
+)
+
+func (x LogStatus) Enum() *LogStatus {
+	p := new(LogStatus)
+	*p = x
+	return p
+}
+
+func (x LogStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[1].Descriptor()
+}
+
+func (LogStatus) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[1]
+}
+
+func (x LogStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogStatus.Descriptor instead.
+func (LogStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{1}
+}
+
+type DeployPhase int32
+
+const (
+	DeployPhase_DEPLOY_PHASE_UNSPECIFIED DeployPhase = 0
+	DeployPhase_DEPLOY_PHASE_CLONE       DeployPhase = 1
+	DeployPhase_DEPLOY_PHASE_INSTALL     DeployPhase = 2
+	DeployPhase_DEPLOY_PHASE_RUN         DeployPhase = 3
+)
+
+// Enum value maps for DeployPhase.
+var (
+	DeployPhase_name = map[int32]string{
+		0: "DEPLOY_PHASE_UNSPECIFIED",
+		1: "DEPLOY_PHASE_CLONE",
+		2: "DEPLOY_PHASE_INSTALL",
+		3: "DEPLOY_PHASE_RUN",
+	}
+	DeployPhase_value = map[string]int32{
+		"DEPLOY_PHASE_UNSPECIFIED": 0,
+		"DEPLOY_PHASE_CLONE":       1,
+		"DEPLOY_PHASE_INSTALL":     2,
+		"DEPLOY_PHASE_RUN":         3,
+	}
+)
+
+func (x DeployPhase) Enum() *DeployPhase {
+	p := new(DeployPhase)
+	*p = x
+	return p
+}
+
+func (x DeployPhase) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeployPhase) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[2].Descriptor()
+}
+
+func (DeployPhase) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[2]
+}
+
+func (x DeployPhase) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeployPhase.Descriptor instead.
+func (DeployPhase) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{2}
+}
+
+type LogSource int32
+
+const (
+	LogSource_LOG_SOURCE_UNSPECIFIED LogSource = 0
+	LogSource_LOG_SOURCE_AGENT       LogSource = 1
+	LogSource_LOG_SOURCE_STDOUT      LogSource = 2
+	LogSource_LOG_SOURCE_STDERR      LogSource = 3
+)
+
+// Enum value maps for LogSource.
+var (
+	LogSource_name = map[int32]string{
+		0: "LOG_SOURCE_UNSPECIFIED",
+		1: "LOG_SOURCE_AGENT",
+		2: "LOG_SOURCE_STDOUT",
+		3: "LOG_SOURCE_STDERR",
+	}
+	LogSource_value = map[string]int32{
+		"LOG_SOURCE_UNSPECIFIED": 0,
+		"LOG_SOURCE_AGENT":       1,
+		"LOG_SOURCE_STDOUT":      2,
+		"LOG_SOURCE_STDERR":      3,
+	}
+)
+
+func (x LogSource) Enum() *LogSource {
+	p := new(LogSource)
+	*p = x
+	return p
+}
+
+func (x LogSource) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LogSource) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[3].Descriptor()
+}
+
+func (LogSource) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[3]
+}
+
+func (x LogSource) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LogSource.Descriptor instead.
+func (LogSource) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{3}
+}
+
+type JobState int32
+
+const (
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	JobState_JOB_STATE_CLONING     JobState = 1
+	JobState_JOB_STATE_INSTALLING  JobState = 2
+	JobState_JOB_STATE_RUNNING     JobState = 3
+	JobState_JOB_STATE_SUCCEEDED   JobState = 4
+	JobState_JOB_STATE_FAILED      JobState = 5
+	JobState_JOB_STATE_CANCELLED   JobState = 6
+	JobState_JOB_STATE_QUEUED      JobState = 7
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_CLONING",
+		2: "JOB_STATE_INSTALLING",
+		3: "JOB_STATE_RUNNING",
+		4: "JOB_STATE_SUCCEEDED",
+		5: "JOB_STATE_FAILED",
+		6: "JOB_STATE_CANCELLED",
+		7: "JOB_STATE_QUEUED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_CLONING":     1,
+		"JOB_STATE_INSTALLING":  2,
+		"JOB_STATE_RUNNING":     3,
+		"JOB_STATE_SUCCEEDED":   4,
+		"JOB_STATE_FAILED":      5,
+		"JOB_STATE_CANCELLED":   6,
+		"JOB_STATE_QUEUED":      7,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[4].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[4]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{4}
+}
+
+type MisfirePolicy int32
+
+const (
+	MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED MisfirePolicy = 0 // equivale a SKIP
+	MisfirePolicy_MISFIRE_POLICY_SKIP        MisfirePolicy = 1 // descarta execuções perdidas durante a indisponibilidade
+	MisfirePolicy_MISFIRE_POLICY_RUN_ONCE    MisfirePolicy = 2 // executa uma vez ao voltar, independente de quantas foram perdidas
+)
+
+// Enum value maps for MisfirePolicy.
+var (
+	MisfirePolicy_name = map[int32]string{
+		0: "MISFIRE_POLICY_UNSPECIFIED",
+		1: "MISFIRE_POLICY_SKIP",
+		2: "MISFIRE_POLICY_RUN_ONCE",
+	}
+	MisfirePolicy_value = map[string]int32{
+		"MISFIRE_POLICY_UNSPECIFIED": 0,
+		"MISFIRE_POLICY_SKIP":        1,
+		"MISFIRE_POLICY_RUN_ONCE":    2,
+	}
+)
+
+func (x MisfirePolicy) Enum() *MisfirePolicy {
+	p := new(MisfirePolicy)
+	*p = x
+	return p
+}
+
+func (x MisfirePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MisfirePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_orchestrator_proto_enumTypes[5].Descriptor()
+}
+
+func (MisfirePolicy) Type() protoreflect.EnumType {
+	return &file_proto_orchestrator_proto_enumTypes[5]
+}
+
+func (x MisfirePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MisfirePolicy.Descriptor instead.
+func (MisfirePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{5}
+}
+
 type DeployRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	BotId         string                 `protobuf:"bytes,1,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"`
-	GitRepo       string                 `protobuf:"bytes,2,opt,name=git_repo,json=gitRepo,proto3" json:"git_repo,omitempty"`
-	Version       string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	BotId        string                 `protobuf:"bytes,1,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"`
+	GitRepo      string                 `protobuf:"bytes,2,opt,name=git_repo,json=gitRepo,proto3" json:"git_repo,omitempty"`
+	Version      string                 `protobuf:"bytes,3,opt,name=version,proto3" json:"version,omitempty"`
+	ForceReclone bool                   `protobuf:"varint,4,opt,name=force_reclone,json=forceReclone,proto3" json:"force_reclone,omitempty"`                                    // descarta o clone local e clona a versão novamente
+	Credential   string                 `protobuf:"bytes,5,opt,name=credential,proto3" json:"credential,omitempty"`                                                             // nome de uma credencial Git cadastrada no agente (token HTTPS ou chave SSH)
+	Env          map[string]string      `protobuf:"bytes,6,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // variáveis de ambiente extras para o bot
+	// Segredos do agente injetados como variáveis de ambiente: "NOME" usa o
+	// segredo NOME na variável NOME; "VAR=nome" usa o segredo nome na variável VAR.
+	SecretRefs     []string `protobuf:"bytes,7,rep,name=secret_refs,json=secretRefs,proto3" json:"secret_refs,omitempty"`
+	TimeoutSeconds uint32   `protobuf:"varint,8,opt,name=timeout_seconds,json=timeoutSeconds,proto3" json:"timeout_seconds,omitempty"` // tempo limite do bot; 0 usa o bot.yaml ou o padrão do agente
+	// Cancela o job se o cliente do ExecuteDeploy desconectar antes do fim.
+	// Por padrão o job continua e pode ser acompanhado com AttachLogs.
+	CancelOnDisconnect bool `protobuf:"varint,9,opt,name=cancel_on_disconnect,json=cancelOnDisconnect,proto3" json:"cancel_on_disconnect,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *DeployRequest) Reset() {
@@ -81,10 +434,74 @@ func (x *DeployRequest) GetVersion() string {
 	return ""
 }
 
+func (x *DeployRequest) GetForceReclone() bool {
+	if x != nil {
+		return x.ForceReclone
+	}
+	return false
+}
+
+func (x *DeployRequest) GetCredential() string {
+	if x != nil {
+		return x.Credential
+	}
+	return ""
+}
+
+func (x *DeployRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *DeployRequest) GetSecretRefs() []string {
+	if x != nil {
+		return x.SecretRefs
+	}
+	return nil
+}
+
+func (x *DeployRequest) GetTimeoutSeconds() uint32 {
+	if x != nil {
+		return x.TimeoutSeconds
+	}
+	return 0
+}
+
+func (x *DeployRequest) GetCancelOnDisconnect() bool {
+	if x != nil {
+		return x.CancelOnDisconnect
+	}
+	return false
+}
+
 type LogResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Line          string                 `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // "INFO", "ERROR", "SUCCESS"
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Line      string                 `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	JobId     string                 `protobuf:"bytes,3,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	Source    LogSource              `protobuf:"varint,4,opt,name=source,proto3,enum=orchestrator.LogSource" json:"source,omitempty"`
+	Status    LogStatus              `protobuf:"varint,5,opt,name=status,proto3,enum=orchestrator.LogStatus" json:"status,omitempty"`
+	Phase     DeployPhase            `protobuf:"varint,6,opt,name=phase,proto3,enum=orchestrator.DeployPhase" json:"phase,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"` // horário do agente quando a linha foi produzida
+	Sequence  int64                  `protobuf:"varint,8,opt,name=sequence,proto3" json:"sequence,omitempty"`  // monotônico por job; é o offset usado em AttachLogs
+	// Maior que zero no aviso enviado a um leitor que ficou para trás: quantas
+	// linhas saíram do buffer do agente antes de serem lidas. O aviso leva a
+	// sequence da última linha descartada e não é gravado no log do job.
+	Dropped int64 `protobuf:"varint,10,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	// A linha ainda não terminou: foi enviada porque o processo ficou sem
+	// escrever por um tempo (ex: um prompt). O restante chega em outra linha.
+	Partial bool `protobuf:"varint,11,opt,name=partial,proto3" json:"partial,omitempty"`
+	// A linha foi reescrita com \r (barras de progresso): substitui a linha de
+	// progresso anterior do mesmo source em vez de se somar a ela.
+	Progress bool `protobuf:"varint,12,opt,name=progress,proto3" json:"progress,omitempty"`
+	// Só a última mensagem do stream, enviada quando o job termina, traz o
+	// resumo da execução.
+	//
+	// Types that are valid to be assigned to Terminal:
+	//
+	//	*LogResponse_Summary
+	Terminal      isLogResponse_Terminal `protobuf_oneof:"terminal"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -126,72 +543,1838 @@ func (x *LogResponse) GetLine() string {
 	return ""
 }
 
-func (x *LogResponse) GetStatus() string {
+func (x *LogResponse) GetJobId() string {
 	if x != nil {
-		return x.Status
+		return x.JobId
 	}
 	return ""
 }
 
-var File_proto_orchestrator_proto protoreflect.FileDescriptor
+func (x *LogResponse) GetSource() LogSource {
+	if x != nil {
+		return x.Source
+	}
+	return LogSource_LOG_SOURCE_UNSPECIFIED
+}
 
-const file_proto_orchestrator_proto_rawDesc = "" +
-	"\n" +
-	"\x18proto/orchestrator.proto\x12\forchestrator\"[\n" +
-	"\rDeployRequest\x12\x15\n" +
-	"\x06bot_id\x18\x01 \x01(\tR\x05botId\x12\x19\n" +
-	"\bgit_repo\x18\x02 \x01(\tR\agitRepo\x12\x18\n" +
-	"\aversion\x18\x03 \x01(\tR\aversion\"9\n" +
-	"\vLogResponse\x12\x12\n" +
-	"\x04line\x18\x01 \x01(\tR\x04line\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status2`\n" +
-	"\x13OrchestratorService\x12I\n" +
-	"\rExecuteDeploy\x12\x1b.orchestrator.DeployRequest\x1a\x19.orchestrator.LogResponse0\x01B\x06Z\x04./pbb\x06proto3"
+func (x *LogResponse) GetStatus() LogStatus {
+	if x != nil {
+		return x.Status
+	}
+	return LogStatus_LOG_STATUS_UNSPECIFIED
+}
 
-var (
-	file_proto_orchestrator_proto_rawDescOnce sync.Once
-	file_proto_orchestrator_proto_rawDescData []byte
-)
+func (x *LogResponse) GetPhase() DeployPhase {
+	if x != nil {
+		return x.Phase
+	}
+	return DeployPhase_DEPLOY_PHASE_UNSPECIFIED
+}
 
-func file_proto_orchestrator_proto_rawDescGZIP() []byte {
-	file_proto_orchestrator_proto_rawDescOnce.Do(func() {
-		file_proto_orchestrator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_orchestrator_proto_rawDesc), len(file_proto_orchestrator_proto_rawDesc)))
-	})
-	return file_proto_orchestrator_proto_rawDescData
+func (x *LogResponse) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
 }
 
-var file_proto_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
-var file_proto_orchestrator_proto_goTypes = []any{
-	(*DeployRequest)(nil), // 0: orchestrator.DeployRequest
-	(*LogResponse)(nil),   // 1: orchestrator.LogResponse
+func (x *LogResponse) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
 }
-var file_proto_orchestrator_proto_depIdxs = []int32{
-	0, // 0: orchestrator.OrchestratorService.ExecuteDeploy:input_type -> orchestrator.DeployRequest
-	1, // 1: orchestrator.OrchestratorService.ExecuteDeploy:output_type -> orchestrator.LogResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *LogResponse) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
 }
 
-func init() { file_proto_orchestrator_proto_init() }
-func file_proto_orchestrator_proto_init() {
-	if File_proto_orchestrator_proto != nil {
-		return
+func (x *LogResponse) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *LogResponse) GetProgress() bool {
+	if x != nil {
+		return x.Progress
+	}
+	return false
+}
+
+func (x *LogResponse) GetTerminal() isLogResponse_Terminal {
+	if x != nil {
+		return x.Terminal
+	}
+	return nil
+}
+
+func (x *LogResponse) GetSummary() *JobSummary {
+	if x != nil {
+		if x, ok := x.Terminal.(*LogResponse_Summary); ok {
+			return x.Summary
+		}
+	}
+	return nil
+}
+
+type isLogResponse_Terminal interface {
+	isLogResponse_Terminal()
+}
+
+type LogResponse_Summary struct {
+	Summary *JobSummary `protobuf:"bytes,9,opt,name=summary,proto3,oneof"`
+}
+
+func (*LogResponse_Summary) isLogResponse_Terminal() {}
+
+// JobSummary resume como o job terminou.
+type JobSummary struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	State           JobState               `protobuf:"varint,1,opt,name=state,proto3,enum=orchestrator.JobState" json:"state,omitempty"`
+	Phase           DeployPhase            `protobuf:"varint,2,opt,name=phase,proto3,enum=orchestrator.DeployPhase" json:"phase,omitempty"` // última fase alcançada
+	FailureReason   FailureReason          `protobuf:"varint,3,opt,name=failure_reason,json=failureReason,proto3,enum=orchestrator.FailureReason" json:"failure_reason,omitempty"`
+	ExitCode        int32                  `protobuf:"varint,4,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"` // -1 quando o bot não chegou a terminar por conta própria
+	Signal          string                 `protobuf:"bytes,5,opt,name=signal,proto3" json:"signal,omitempty"`                      // sinal que encerrou o processo, ex: "SIGKILL"
+	Error           string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	ResolvedCommit  string                 `protobuf:"bytes,7,opt,name=resolved_commit,json=resolvedCommit,proto3" json:"resolved_commit,omitempty"`
+	LimitExceeded   string                 `protobuf:"bytes,8,opt,name=limit_exceeded,json=limitExceeded,proto3" json:"limit_exceeded,omitempty"`
+	QueuedDuration  *durationpb.Duration   `protobuf:"bytes,9,opt,name=queued_duration,json=queuedDuration,proto3" json:"queued_duration,omitempty"`
+	CloneDuration   *durationpb.Duration   `protobuf:"bytes,10,opt,name=clone_duration,json=cloneDuration,proto3" json:"clone_duration,omitempty"`
+	InstallDuration *durationpb.Duration   `protobuf:"bytes,11,opt,name=install_duration,json=installDuration,proto3" json:"install_duration,omitempty"`
+	RunDuration     *durationpb.Duration   `protobuf:"bytes,12,opt,name=run_duration,json=runDuration,proto3" json:"run_duration,omitempty"`
+	TotalDuration   *durationpb.Duration   `protobuf:"bytes,13,opt,name=total_duration,json=totalDuration,proto3" json:"total_duration,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *JobSummary) Reset() {
+	*x = JobSummary{}
+	mi := &file_proto_orchestrator_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobSummary) ProtoMessage() {}
+
+func (x *JobSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobSummary.ProtoReflect.Descriptor instead.
+func (*JobSummary) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *JobSummary) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *JobSummary) GetPhase() DeployPhase {
+	if x != nil {
+		return x.Phase
+	}
+	return DeployPhase_DEPLOY_PHASE_UNSPECIFIED
+}
+
+func (x *JobSummary) GetFailureReason() FailureReason {
+	if x != nil {
+		return x.FailureReason
+	}
+	return FailureReason_FAILURE_REASON_UNSPECIFIED
+}
+
+func (x *JobSummary) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *JobSummary) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *JobSummary) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *JobSummary) GetResolvedCommit() string {
+	if x != nil {
+		return x.ResolvedCommit
+	}
+	return ""
+}
+
+func (x *JobSummary) GetLimitExceeded() string {
+	if x != nil {
+		return x.LimitExceeded
+	}
+	return ""
+}
+
+func (x *JobSummary) GetQueuedDuration() *durationpb.Duration {
+	if x != nil {
+		return x.QueuedDuration
+	}
+	return nil
+}
+
+func (x *JobSummary) GetCloneDuration() *durationpb.Duration {
+	if x != nil {
+		return x.CloneDuration
+	}
+	return nil
+}
+
+func (x *JobSummary) GetInstallDuration() *durationpb.Duration {
+	if x != nil {
+		return x.InstallDuration
+	}
+	return nil
+}
+
+func (x *JobSummary) GetRunDuration() *durationpb.Duration {
+	if x != nil {
+		return x.RunDuration
+	}
+	return nil
+}
+
+func (x *JobSummary) GetTotalDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TotalDuration
+	}
+	return nil
+}
+
+type Job struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	JobId          string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	BotId          string                 `protobuf:"bytes,2,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"`
+	GitRepo        string                 `protobuf:"bytes,3,opt,name=git_repo,json=gitRepo,proto3" json:"git_repo,omitempty"`
+	Version        string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	State          JobState               `protobuf:"varint,5,opt,name=state,proto3,enum=orchestrator.JobState" json:"state,omitempty"`
+	StartedAt      *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	ExitCode       int32                  `protobuf:"varint,8,opt,name=exit_code,json=exitCode,proto3" json:"exit_code,omitempty"` // -1 quando o bot não chegou a ser executado
+	Error          string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	ScheduleId     string                 `protobuf:"bytes,10,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`             // preenchido quando o job foi criado por um agendamento
+	ResolvedCommit string                 `protobuf:"bytes,11,opt,name=resolved_commit,json=resolvedCommit,proto3" json:"resolved_commit,omitempty"` // SHA efetivamente implantado
+	LimitExceeded  string                 `protobuf:"bytes,12,opt,name=limit_exceeded,json=limitExceeded,proto3" json:"limit_exceeded,omitempty"`    // preenchido quando o agente encerrou o bot por um limite: "timeout", "install_timeout", "memory" ou "open_files"
+	Summary        *JobSummary            `protobuf:"bytes,13,opt,name=summary,proto3" json:"summary,omitempty"`                                     // preenchido quando o job termina
+	RequestedBy    string                 `protobuf:"bytes,14,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`          // identidade de quem pediu a execução; em jobs agendados, de quem criou o agendamento
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_proto_orchestrator_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Job) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *Job) GetBotId() string {
+	if x != nil {
+		return x.BotId
+	}
+	return ""
+}
+
+func (x *Job) GetGitRepo() string {
+	if x != nil {
+		return x.GitRepo
+	}
+	return ""
+}
+
+func (x *Job) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Job) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Job) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Job) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *Job) GetResolvedCommit() string {
+	if x != nil {
+		return x.ResolvedCommit
+	}
+	return ""
+}
+
+func (x *Job) GetLimitExceeded() string {
+	if x != nil {
+		return x.LimitExceeded
+	}
+	return ""
+}
+
+func (x *Job) GetSummary() *JobSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *Job) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type ListJobsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BotId         string                 `protobuf:"bytes,1,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"` // opcional: filtra pelo bot
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`             // opcional: 0 devolve todos
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsRequest) Reset() {
+	*x = ListJobsRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsRequest) ProtoMessage() {}
+
+func (x *ListJobsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsRequest.ProtoReflect.Descriptor instead.
+func (*ListJobsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListJobsRequest) GetBotId() string {
+	if x != nil {
+		return x.BotId
+	}
+	return ""
+}
+
+func (x *ListJobsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListJobsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jobs          []*Job                 `protobuf:"bytes,1,rep,name=jobs,proto3" json:"jobs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListJobsResponse) Reset() {
+	*x = ListJobsResponse{}
+	mi := &file_proto_orchestrator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListJobsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListJobsResponse) ProtoMessage() {}
+
+func (x *ListJobsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListJobsResponse.ProtoReflect.Descriptor instead.
+func (*ListJobsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListJobsResponse) GetJobs() []*Job {
+	if x != nil {
+		return x.Jobs
+	}
+	return nil
+}
+
+type CancelJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type AttachLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	FromOffset    int64                  `protobuf:"varint,2,opt,name=from_offset,json=fromOffset,proto3" json:"from_offset,omitempty"` // sequence da primeira linha a ser reenviada
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachLogsRequest) Reset() {
+	*x = AttachLogsRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachLogsRequest) ProtoMessage() {}
+
+func (x *AttachLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachLogsRequest.ProtoReflect.Descriptor instead.
+func (*AttachLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *AttachLogsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *AttachLogsRequest) GetFromOffset() int64 {
+	if x != nil {
+		return x.FromOffset
+	}
+	return 0
+}
+
+type GetJobLogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobLogsRequest) Reset() {
+	*x = GetJobLogsRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobLogsRequest) ProtoMessage() {}
+
+func (x *GetJobLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobLogsRequest.ProtoReflect.Descriptor instead.
+func (*GetJobLogsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetJobLogsRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+// LogEntry é uma linha do log persistido de um job.
+type LogEntry struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Time     *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Source   LogSource              `protobuf:"varint,2,opt,name=source,proto3,enum=orchestrator.LogSource" json:"source,omitempty"`
+	Line     string                 `protobuf:"bytes,4,opt,name=line,proto3" json:"line,omitempty"`
+	Status   LogStatus              `protobuf:"varint,5,opt,name=status,proto3,enum=orchestrator.LogStatus" json:"status,omitempty"`
+	Phase    DeployPhase            `protobuf:"varint,6,opt,name=phase,proto3,enum=orchestrator.DeployPhase" json:"phase,omitempty"`
+	Sequence int64                  `protobuf:"varint,7,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// Bytes originais da saída do processo, presentes só quando line foi
+	// convertida de outra codificação (output_encoding do bot.yaml) ou tinha
+	// bytes que não são UTF-8.
+	Raw           []byte `protobuf:"bytes,8,opt,name=raw,proto3" json:"raw,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogEntry) Reset() {
+	*x = LogEntry{}
+	mi := &file_proto_orchestrator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogEntry) ProtoMessage() {}
+
+func (x *LogEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogEntry.ProtoReflect.Descriptor instead.
+func (*LogEntry) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LogEntry) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *LogEntry) GetSource() LogSource {
+	if x != nil {
+		return x.Source
+	}
+	return LogSource_LOG_SOURCE_UNSPECIFIED
+}
+
+func (x *LogEntry) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+func (x *LogEntry) GetStatus() LogStatus {
+	if x != nil {
+		return x.Status
+	}
+	return LogStatus_LOG_STATUS_UNSPECIFIED
+}
+
+func (x *LogEntry) GetPhase() DeployPhase {
+	if x != nil {
+		return x.Phase
+	}
+	return DeployPhase_DEPLOY_PHASE_UNSPECIFIED
+}
+
+func (x *LogEntry) GetSequence() int64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *LogEntry) GetRaw() []byte {
+	if x != nil {
+		return x.Raw
+	}
+	return nil
+}
+
+type Schedule struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Cron          string                 `protobuf:"bytes,2,opt,name=cron,proto3" json:"cron,omitempty"`         // ex: "0 8 * * MON-FRI", "*/15 * * * *"
+	Timezone      string                 `protobuf:"bytes,3,opt,name=timezone,proto3" json:"timezone,omitempty"` // IANA, ex: "America/Sao_Paulo"; vazio usa o fuso do agente
+	Bot           *DeployRequest         `protobuf:"bytes,4,opt,name=bot,proto3" json:"bot,omitempty"`
+	MisfirePolicy MisfirePolicy          `protobuf:"varint,5,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=orchestrator.MisfirePolicy" json:"misfire_policy,omitempty"`
+	Paused        bool                   `protobuf:"varint,6,opt,name=paused,proto3" json:"paused,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	NextRunAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=next_run_at,json=nextRunAt,proto3" json:"next_run_at,omitempty"`
+	LastRunAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=last_run_at,json=lastRunAt,proto3" json:"last_run_at,omitempty"`
+	LastJobId     string                 `protobuf:"bytes,10,opt,name=last_job_id,json=lastJobId,proto3" json:"last_job_id,omitempty"`
+	LastError     string                 `protobuf:"bytes,11,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedBy     string                 `protobuf:"bytes,12,opt,name=created_by,json=createdBy,proto3" json:"created_by,omitempty"` // identidade de quem criou o agendamento
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Schedule) Reset() {
+	*x = Schedule{}
+	mi := &file_proto_orchestrator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Schedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Schedule) ProtoMessage() {}
+
+func (x *Schedule) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Schedule.ProtoReflect.Descriptor instead.
+func (*Schedule) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Schedule) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *Schedule) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *Schedule) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *Schedule) GetBot() *DeployRequest {
+	if x != nil {
+		return x.Bot
+	}
+	return nil
+}
+
+func (x *Schedule) GetMisfirePolicy() MisfirePolicy {
+	if x != nil {
+		return x.MisfirePolicy
+	}
+	return MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED
+}
+
+func (x *Schedule) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *Schedule) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Schedule) GetNextRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.NextRunAt
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastRunAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastRunAt
+	}
+	return nil
+}
+
+func (x *Schedule) GetLastJobId() string {
+	if x != nil {
+		return x.LastJobId
+	}
+	return ""
+}
+
+func (x *Schedule) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *Schedule) GetCreatedBy() string {
+	if x != nil {
+		return x.CreatedBy
+	}
+	return ""
+}
+
+type CreateScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cron          string                 `protobuf:"bytes,1,opt,name=cron,proto3" json:"cron,omitempty"`
+	Timezone      string                 `protobuf:"bytes,2,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	Bot           *DeployRequest         `protobuf:"bytes,3,opt,name=bot,proto3" json:"bot,omitempty"`
+	MisfirePolicy MisfirePolicy          `protobuf:"varint,4,opt,name=misfire_policy,json=misfirePolicy,proto3,enum=orchestrator.MisfirePolicy" json:"misfire_policy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateScheduleRequest) Reset() {
+	*x = CreateScheduleRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateScheduleRequest) ProtoMessage() {}
+
+func (x *CreateScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateScheduleRequest.ProtoReflect.Descriptor instead.
+func (*CreateScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateScheduleRequest) GetCron() string {
+	if x != nil {
+		return x.Cron
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *CreateScheduleRequest) GetBot() *DeployRequest {
+	if x != nil {
+		return x.Bot
+	}
+	return nil
+}
+
+func (x *CreateScheduleRequest) GetMisfirePolicy() MisfirePolicy {
+	if x != nil {
+		return x.MisfirePolicy
+	}
+	return MisfirePolicy_MISFIRE_POLICY_UNSPECIFIED
+}
+
+type ListSchedulesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesRequest) Reset() {
+	*x = ListSchedulesRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesRequest) ProtoMessage() {}
+
+func (x *ListSchedulesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesRequest.ProtoReflect.Descriptor instead.
+func (*ListSchedulesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{13}
+}
+
+type ListSchedulesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Schedules     []*Schedule            `protobuf:"bytes,1,rep,name=schedules,proto3" json:"schedules,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSchedulesResponse) Reset() {
+	*x = ListSchedulesResponse{}
+	mi := &file_proto_orchestrator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSchedulesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSchedulesResponse) ProtoMessage() {}
+
+func (x *ListSchedulesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSchedulesResponse.ProtoReflect.Descriptor instead.
+func (*ListSchedulesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListSchedulesResponse) GetSchedules() []*Schedule {
+	if x != nil {
+		return x.Schedules
+	}
+	return nil
+}
+
+type DeleteScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleRequest) Reset() {
+	*x = DeleteScheduleRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleRequest) ProtoMessage() {}
+
+func (x *DeleteScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleRequest.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteScheduleRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+type DeleteScheduleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteScheduleResponse) Reset() {
+	*x = DeleteScheduleResponse{}
+	mi := &file_proto_orchestrator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteScheduleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteScheduleResponse) ProtoMessage() {}
+
+func (x *DeleteScheduleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteScheduleResponse.ProtoReflect.Descriptor instead.
+func (*DeleteScheduleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{16}
+}
+
+type PauseScheduleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduleId    string                 `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	Paused        bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"` // false retoma o agendamento
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseScheduleRequest) Reset() {
+	*x = PauseScheduleRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseScheduleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseScheduleRequest) ProtoMessage() {}
+
+func (x *PauseScheduleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseScheduleRequest.ProtoReflect.Descriptor instead.
+func (*PauseScheduleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PauseScheduleRequest) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *PauseScheduleRequest) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type DeleteBotVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BotId         string                 `protobuf:"bytes,1,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBotVersionRequest) Reset() {
+	*x = DeleteBotVersionRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBotVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBotVersionRequest) ProtoMessage() {}
+
+func (x *DeleteBotVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBotVersionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBotVersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *DeleteBotVersionRequest) GetBotId() string {
+	if x != nil {
+		return x.BotId
+	}
+	return ""
+}
+
+func (x *DeleteBotVersionRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+type DeleteBotVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FreedBytes    int64                  `protobuf:"varint,1,opt,name=freed_bytes,json=freedBytes,proto3" json:"freed_bytes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBotVersionResponse) Reset() {
+	*x = DeleteBotVersionResponse{}
+	mi := &file_proto_orchestrator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBotVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBotVersionResponse) ProtoMessage() {}
+
+func (x *DeleteBotVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBotVersionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBotVersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *DeleteBotVersionResponse) GetFreedBytes() int64 {
+	if x != nil {
+		return x.FreedBytes
+	}
+	return 0
+}
+
+// PruneBotsRequest aplica a política de retenção. Campos zerados usam a
+// política configurada no agente.
+type PruneBotsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DryRun        bool                   `protobuf:"varint,1,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`                        // só informa o que seria removido
+	KeepVersions  uint32                 `protobuf:"varint,2,opt,name=keep_versions,json=keepVersions,proto3" json:"keep_versions,omitempty"`      // versões mantidas por bot, das usadas mais recentemente
+	MaxTotalBytes int64                  `protobuf:"varint,3,opt,name=max_total_bytes,json=maxTotalBytes,proto3" json:"max_total_bytes,omitempty"` // tamanho máximo somado de ./bots e do cache de ambientes
+	Ttl           *durationpb.Duration   `protobuf:"bytes,4,opt,name=ttl,proto3" json:"ttl,omitempty"`                                             // tempo máximo desde a última execução
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PruneBotsRequest) Reset() {
+	*x = PruneBotsRequest{}
+	mi := &file_proto_orchestrator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneBotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneBotsRequest) ProtoMessage() {}
+
+func (x *PruneBotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneBotsRequest.ProtoReflect.Descriptor instead.
+func (*PruneBotsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *PruneBotsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *PruneBotsRequest) GetKeepVersions() uint32 {
+	if x != nil {
+		return x.KeepVersions
+	}
+	return 0
+}
+
+func (x *PruneBotsRequest) GetMaxTotalBytes() int64 {
+	if x != nil {
+		return x.MaxTotalBytes
+	}
+	return 0
+}
+
+func (x *PruneBotsRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+type PruneBotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*PrunedItem          `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	FreedBytes    int64                  `protobuf:"varint,2,opt,name=freed_bytes,json=freedBytes,proto3" json:"freed_bytes,omitempty"`
+	TotalBytes    int64                  `protobuf:"varint,3,opt,name=total_bytes,json=totalBytes,proto3" json:"total_bytes,omitempty"` // tamanho total depois da limpeza (ou estimado, em dry_run)
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PruneBotsResponse) Reset() {
+	*x = PruneBotsResponse{}
+	mi := &file_proto_orchestrator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PruneBotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PruneBotsResponse) ProtoMessage() {}
+
+func (x *PruneBotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PruneBotsResponse.ProtoReflect.Descriptor instead.
+func (*PruneBotsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PruneBotsResponse) GetItems() []*PrunedItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *PruneBotsResponse) GetFreedBytes() int64 {
+	if x != nil {
+		return x.FreedBytes
+	}
+	return 0
+}
+
+func (x *PruneBotsResponse) GetTotalBytes() int64 {
+	if x != nil {
+		return x.TotalBytes
+	}
+	return 0
+}
+
+func (x *PruneBotsResponse) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// PrunedItem é uma versão de bot ou um ambiente do cache removido (ou que
+// seria removido, em dry_run).
+type PrunedItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BotId         string                 `protobuf:"bytes,1,opt,name=bot_id,json=botId,proto3" json:"bot_id,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	EnvKey        string                 `protobuf:"bytes,3,opt,name=env_key,json=envKey,proto3" json:"env_key,omitempty"` // preenchido para ambientes do cache de dependências
+	SizeBytes     int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	LastUsedAt    *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_used_at,json=lastUsedAt,proto3" json:"last_used_at,omitempty"`
+	Reason        string                 `protobuf:"bytes,6,opt,name=reason,proto3" json:"reason,omitempty"` // "keep_versions", "ttl" ou "max_total_bytes"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrunedItem) Reset() {
+	*x = PrunedItem{}
+	mi := &file_proto_orchestrator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrunedItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrunedItem) ProtoMessage() {}
+
+func (x *PrunedItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_orchestrator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrunedItem.ProtoReflect.Descriptor instead.
+func (*PrunedItem) Descriptor() ([]byte, []int) {
+	return file_proto_orchestrator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PrunedItem) GetBotId() string {
+	if x != nil {
+		return x.BotId
+	}
+	return ""
+}
+
+func (x *PrunedItem) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PrunedItem) GetEnvKey() string {
+	if x != nil {
+		return x.EnvKey
+	}
+	return ""
+}
+
+func (x *PrunedItem) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *PrunedItem) GetLastUsedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastUsedAt
+	}
+	return nil
+}
+
+func (x *PrunedItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_proto_orchestrator_proto protoreflect.FileDescriptor
+
+const file_proto_orchestrator_proto_rawDesc = "" +
+	"\n" +
+	"\x18proto/orchestrator.proto\x12\forchestrator\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x8c\x03\n" +
+	"\rDeployRequest\x12\x15\n" +
+	"\x06bot_id\x18\x01 \x01(\tR\x05botId\x12\x19\n" +
+	"\bgit_repo\x18\x02 \x01(\tR\agitRepo\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\tR\aversion\x12#\n" +
+	"\rforce_reclone\x18\x04 \x01(\bR\fforceReclone\x12\x1e\n" +
+	"\n" +
+	"credential\x18\x05 \x01(\tR\n" +
+	"credential\x126\n" +
+	"\x03env\x18\x06 \x03(\v2$.orchestrator.DeployRequest.EnvEntryR\x03env\x12\x1f\n" +
+	"\vsecret_refs\x18\a \x03(\tR\n" +
+	"secretRefs\x12'\n" +
+	"\x0ftimeout_seconds\x18\b \x01(\rR\x0etimeoutSeconds\x120\n" +
+	"\x14cancel_on_disconnect\x18\t \x01(\bR\x12cancelOnDisconnect\x1a6\n" +
+	"\bEnvEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb9\x03\n" +
+	"\vLogResponse\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\tR\x04line\x12\x15\n" +
+	"\x06job_id\x18\x03 \x01(\tR\x05jobId\x12/\n" +
+	"\x06source\x18\x04 \x01(\x0e2\x17.orchestrator.LogSourceR\x06source\x12/\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x17.orchestrator.LogStatusR\x06status\x12/\n" +
+	"\x05phase\x18\x06 \x01(\x0e2\x19.orchestrator.DeployPhaseR\x05phase\x128\n" +
+	"\ttimestamp\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1a\n" +
+	"\bsequence\x18\b \x01(\x03R\bsequence\x12\x18\n" +
+	"\adropped\x18\n" +
+	" \x01(\x03R\adropped\x12\x18\n" +
+	"\apartial\x18\v \x01(\bR\apartial\x12\x1a\n" +
+	"\bprogress\x18\f \x01(\bR\bprogress\x124\n" +
+	"\asummary\x18\t \x01(\v2\x18.orchestrator.JobSummaryH\x00R\asummaryB\n" +
+	"\n" +
+	"\bterminalJ\x04\b\x02\x10\x03\"\x96\x05\n" +
+	"\n" +
+	"JobSummary\x12,\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x16.orchestrator.JobStateR\x05state\x12/\n" +
+	"\x05phase\x18\x02 \x01(\x0e2\x19.orchestrator.DeployPhaseR\x05phase\x12B\n" +
+	"\x0efailure_reason\x18\x03 \x01(\x0e2\x1b.orchestrator.FailureReasonR\rfailureReason\x12\x1b\n" +
+	"\texit_code\x18\x04 \x01(\x05R\bexitCode\x12\x16\n" +
+	"\x06signal\x18\x05 \x01(\tR\x06signal\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\x12'\n" +
+	"\x0fresolved_commit\x18\a \x01(\tR\x0eresolvedCommit\x12%\n" +
+	"\x0elimit_exceeded\x18\b \x01(\tR\rlimitExceeded\x12B\n" +
+	"\x0fqueued_duration\x18\t \x01(\v2\x19.google.protobuf.DurationR\x0equeuedDuration\x12@\n" +
+	"\x0eclone_duration\x18\n" +
+	" \x01(\v2\x19.google.protobuf.DurationR\rcloneDuration\x12D\n" +
+	"\x10install_duration\x18\v \x01(\v2\x19.google.protobuf.DurationR\x0finstallDuration\x12<\n" +
+	"\frun_duration\x18\f \x01(\v2\x19.google.protobuf.DurationR\vrunDuration\x12@\n" +
+	"\x0etotal_duration\x18\r \x01(\v2\x19.google.protobuf.DurationR\rtotalDuration\"\x89\x04\n" +
+	"\x03Job\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x15\n" +
+	"\x06bot_id\x18\x02 \x01(\tR\x05botId\x12\x19\n" +
+	"\bgit_repo\x18\x03 \x01(\tR\agitRepo\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12,\n" +
+	"\x05state\x18\x05 \x01(\x0e2\x16.orchestrator.JobStateR\x05state\x129\n" +
+	"\n" +
+	"started_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x1b\n" +
+	"\texit_code\x18\b \x01(\x05R\bexitCode\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1f\n" +
+	"\vschedule_id\x18\n" +
+	" \x01(\tR\n" +
+	"scheduleId\x12'\n" +
+	"\x0fresolved_commit\x18\v \x01(\tR\x0eresolvedCommit\x12%\n" +
+	"\x0elimit_exceeded\x18\f \x01(\tR\rlimitExceeded\x122\n" +
+	"\asummary\x18\r \x01(\v2\x18.orchestrator.JobSummaryR\asummary\x12!\n" +
+	"\frequested_by\x18\x0e \x01(\tR\vrequestedBy\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\">\n" +
+	"\x0fListJobsRequest\x12\x15\n" +
+	"\x06bot_id\x18\x01 \x01(\tR\x05botId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"9\n" +
+	"\x10ListJobsResponse\x12%\n" +
+	"\x04jobs\x18\x01 \x03(\v2\x11.orchestrator.JobR\x04jobs\")\n" +
+	"\x10CancelJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"K\n" +
+	"\x11AttachLogsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12\x1f\n" +
+	"\vfrom_offset\x18\x02 \x01(\x03R\n" +
+	"fromOffset\"*\n" +
+	"\x11GetJobLogsRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x95\x02\n" +
+	"\bLogEntry\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12/\n" +
+	"\x06source\x18\x02 \x01(\x0e2\x17.orchestrator.LogSourceR\x06source\x12\x12\n" +
+	"\x04line\x18\x04 \x01(\tR\x04line\x12/\n" +
+	"\x06status\x18\x05 \x01(\x0e2\x17.orchestrator.LogStatusR\x06status\x12/\n" +
+	"\x05phase\x18\x06 \x01(\x0e2\x19.orchestrator.DeployPhaseR\x05phase\x12\x1a\n" +
+	"\bsequence\x18\a \x01(\x03R\bsequence\x12\x10\n" +
+	"\x03raw\x18\b \x01(\fR\x03rawJ\x04\b\x03\x10\x04\"\xf7\x03\n" +
+	"\bSchedule\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x12\n" +
+	"\x04cron\x18\x02 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x03 \x01(\tR\btimezone\x12-\n" +
+	"\x03bot\x18\x04 \x01(\v2\x1b.orchestrator.DeployRequestR\x03bot\x12B\n" +
+	"\x0emisfire_policy\x18\x05 \x01(\x0e2\x1b.orchestrator.MisfirePolicyR\rmisfirePolicy\x12\x16\n" +
+	"\x06paused\x18\x06 \x01(\bR\x06paused\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12:\n" +
+	"\vnext_run_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tnextRunAt\x12:\n" +
+	"\vlast_run_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tlastRunAt\x12\x1e\n" +
+	"\vlast_job_id\x18\n" +
+	" \x01(\tR\tlastJobId\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\v \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_by\x18\f \x01(\tR\tcreatedBy\"\xba\x01\n" +
+	"\x15CreateScheduleRequest\x12\x12\n" +
+	"\x04cron\x18\x01 \x01(\tR\x04cron\x12\x1a\n" +
+	"\btimezone\x18\x02 \x01(\tR\btimezone\x12-\n" +
+	"\x03bot\x18\x03 \x01(\v2\x1b.orchestrator.DeployRequestR\x03bot\x12B\n" +
+	"\x0emisfire_policy\x18\x04 \x01(\x0e2\x1b.orchestrator.MisfirePolicyR\rmisfirePolicy\"\x16\n" +
+	"\x14ListSchedulesRequest\"M\n" +
+	"\x15ListSchedulesResponse\x124\n" +
+	"\tschedules\x18\x01 \x03(\v2\x16.orchestrator.ScheduleR\tschedules\"8\n" +
+	"\x15DeleteScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\"\x18\n" +
+	"\x16DeleteScheduleResponse\"O\n" +
+	"\x14PauseScheduleRequest\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\"J\n" +
+	"\x17DeleteBotVersionRequest\x12\x15\n" +
+	"\x06bot_id\x18\x01 \x01(\tR\x05botId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\";\n" +
+	"\x18DeleteBotVersionResponse\x12\x1f\n" +
+	"\vfreed_bytes\x18\x01 \x01(\x03R\n" +
+	"freedBytes\"\xa5\x01\n" +
+	"\x10PruneBotsRequest\x12\x17\n" +
+	"\adry_run\x18\x01 \x01(\bR\x06dryRun\x12#\n" +
+	"\rkeep_versions\x18\x02 \x01(\rR\fkeepVersions\x12&\n" +
+	"\x0fmax_total_bytes\x18\x03 \x01(\x03R\rmaxTotalBytes\x12+\n" +
+	"\x03ttl\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\"\x9e\x01\n" +
+	"\x11PruneBotsResponse\x12.\n" +
+	"\x05items\x18\x01 \x03(\v2\x18.orchestrator.PrunedItemR\x05items\x12\x1f\n" +
+	"\vfreed_bytes\x18\x02 \x01(\x03R\n" +
+	"freedBytes\x12\x1f\n" +
+	"\vtotal_bytes\x18\x03 \x01(\x03R\n" +
+	"totalBytes\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"\xcb\x01\n" +
+	"\n" +
+	"PrunedItem\x12\x15\n" +
+	"\x06bot_id\x18\x01 \x01(\tR\x05botId\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x17\n" +
+	"\aenv_key\x18\x03 \x01(\tR\x06envKey\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12<\n" +
+	"\flast_used_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastUsedAt\x12\x16\n" +
+	"\x06reason\x18\x06 \x01(\tR\x06reason*\x87\x02\n" +
+	"\rFailureReason\x12\x1e\n" +
+	"\x1aFAILURE_REASON_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18FAILURE_REASON_CANCELLED\x10\x01\x12!\n" +
+	"\x1dFAILURE_REASON_INVALID_CONFIG\x10\x02\x12\x18\n" +
+	"\x14FAILURE_REASON_CLONE\x10\x03\x12\x1f\n" +
+	"\x1bFAILURE_REASON_DEPENDENCIES\x10\x04\x12\x1b\n" +
+	"\x17FAILURE_REASON_BOT_EXIT\x10\x05\x12\x1a\n" +
+	"\x16FAILURE_REASON_TIMEOUT\x10\x06\x12!\n" +
+	"\x1dFAILURE_REASON_RESOURCE_LIMIT\x10\a*\x9b\x01\n" +
+	"\tLogStatus\x12\x1a\n" +
+	"\x16LOG_STATUS_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fLOG_STATUS_INFO\x10\x01\x12\x16\n" +
+	"\x12LOG_STATUS_SUCCESS\x10\x02\x12\x14\n" +
+	"\x10LOG_STATUS_ERROR\x10\x03\x12\x18\n" +
+	"\x14LOG_STATUS_CANCELLED\x10\x04\x12\x15\n" +
+	"\x11LOG_STATUS_QUEUED\x10\x05*s\n" +
+	"\vDeployPhase\x12\x1c\n" +
+	"\x18DEPLOY_PHASE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12DEPLOY_PHASE_CLONE\x10\x01\x12\x18\n" +
+	"\x14DEPLOY_PHASE_INSTALL\x10\x02\x12\x14\n" +
+	"\x10DEPLOY_PHASE_RUN\x10\x03*k\n" +
+	"\tLogSource\x12\x1a\n" +
+	"\x16LOG_SOURCE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10LOG_SOURCE_AGENT\x10\x01\x12\x15\n" +
+	"\x11LOG_SOURCE_STDOUT\x10\x02\x12\x15\n" +
+	"\x11LOG_SOURCE_STDERR\x10\x03*\xcb\x01\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11JOB_STATE_CLONING\x10\x01\x12\x18\n" +
+	"\x14JOB_STATE_INSTALLING\x10\x02\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x03\x12\x17\n" +
+	"\x13JOB_STATE_SUCCEEDED\x10\x04\x12\x14\n" +
+	"\x10JOB_STATE_FAILED\x10\x05\x12\x17\n" +
+	"\x13JOB_STATE_CANCELLED\x10\x06\x12\x14\n" +
+	"\x10JOB_STATE_QUEUED\x10\a*e\n" +
+	"\rMisfirePolicy\x12\x1e\n" +
+	"\x1aMISFIRE_POLICY_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13MISFIRE_POLICY_SKIP\x10\x01\x12\x1b\n" +
+	"\x17MISFIRE_POLICY_RUN_ONCE\x10\x022\xbe\a\n" +
+	"\x13OrchestratorService\x12I\n" +
+	"\rExecuteDeploy\x12\x1b.orchestrator.DeployRequest\x1a\x19.orchestrator.LogResponse0\x01\x128\n" +
+	"\x06GetJob\x12\x1b.orchestrator.GetJobRequest\x1a\x11.orchestrator.Job\x12I\n" +
+	"\bListJobs\x12\x1d.orchestrator.ListJobsRequest\x1a\x1e.orchestrator.ListJobsResponse\x12>\n" +
+	"\tCancelJob\x12\x1e.orchestrator.CancelJobRequest\x1a\x11.orchestrator.Job\x12J\n" +
+	"\n" +
+	"AttachLogs\x12\x1f.orchestrator.AttachLogsRequest\x1a\x19.orchestrator.LogResponse0\x01\x12G\n" +
+	"\n" +
+	"GetJobLogs\x12\x1f.orchestrator.GetJobLogsRequest\x1a\x16.orchestrator.LogEntry0\x01\x12M\n" +
+	"\x0eCreateSchedule\x12#.orchestrator.CreateScheduleRequest\x1a\x16.orchestrator.Schedule\x12X\n" +
+	"\rListSchedules\x12\".orchestrator.ListSchedulesRequest\x1a#.orchestrator.ListSchedulesResponse\x12[\n" +
+	"\x0eDeleteSchedule\x12#.orchestrator.DeleteScheduleRequest\x1a$.orchestrator.DeleteScheduleResponse\x12K\n" +
+	"\rPauseSchedule\x12\".orchestrator.PauseScheduleRequest\x1a\x16.orchestrator.Schedule\x12a\n" +
+	"\x10DeleteBotVersion\x12%.orchestrator.DeleteBotVersionRequest\x1a&.orchestrator.DeleteBotVersionResponse\x12L\n" +
+	"\tPruneBots\x12\x1e.orchestrator.PruneBotsRequest\x1a\x1f.orchestrator.PruneBotsResponseB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_proto_orchestrator_proto_rawDescOnce sync.Once
+	file_proto_orchestrator_proto_rawDescData []byte
+)
+
+func file_proto_orchestrator_proto_rawDescGZIP() []byte {
+	file_proto_orchestrator_proto_rawDescOnce.Do(func() {
+		file_proto_orchestrator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_orchestrator_proto_rawDesc), len(file_proto_orchestrator_proto_rawDesc)))
+	})
+	return file_proto_orchestrator_proto_rawDescData
+}
+
+var file_proto_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_proto_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_proto_orchestrator_proto_goTypes = []any{
+	(FailureReason)(0),               // 0: orchestrator.FailureReason
+	(LogStatus)(0),                   // 1: orchestrator.LogStatus
+	(DeployPhase)(0),                 // 2: orchestrator.DeployPhase
+	(LogSource)(0),                   // 3: orchestrator.LogSource
+	(JobState)(0),                    // 4: orchestrator.JobState
+	(MisfirePolicy)(0),               // 5: orchestrator.MisfirePolicy
+	(*DeployRequest)(nil),            // 6: orchestrator.DeployRequest
+	(*LogResponse)(nil),              // 7: orchestrator.LogResponse
+	(*JobSummary)(nil),               // 8: orchestrator.JobSummary
+	(*Job)(nil),                      // 9: orchestrator.Job
+	(*GetJobRequest)(nil),            // 10: orchestrator.GetJobRequest
+	(*ListJobsRequest)(nil),          // 11: orchestrator.ListJobsRequest
+	(*ListJobsResponse)(nil),         // 12: orchestrator.ListJobsResponse
+	(*CancelJobRequest)(nil),         // 13: orchestrator.CancelJobRequest
+	(*AttachLogsRequest)(nil),        // 14: orchestrator.AttachLogsRequest
+	(*GetJobLogsRequest)(nil),        // 15: orchestrator.GetJobLogsRequest
+	(*LogEntry)(nil),                 // 16: orchestrator.LogEntry
+	(*Schedule)(nil),                 // 17: orchestrator.Schedule
+	(*CreateScheduleRequest)(nil),    // 18: orchestrator.CreateScheduleRequest
+	(*ListSchedulesRequest)(nil),     // 19: orchestrator.ListSchedulesRequest
+	(*ListSchedulesResponse)(nil),    // 20: orchestrator.ListSchedulesResponse
+	(*DeleteScheduleRequest)(nil),    // 21: orchestrator.DeleteScheduleRequest
+	(*DeleteScheduleResponse)(nil),   // 22: orchestrator.DeleteScheduleResponse
+	(*PauseScheduleRequest)(nil),     // 23: orchestrator.PauseScheduleRequest
+	(*DeleteBotVersionRequest)(nil),  // 24: orchestrator.DeleteBotVersionRequest
+	(*DeleteBotVersionResponse)(nil), // 25: orchestrator.DeleteBotVersionResponse
+	(*PruneBotsRequest)(nil),         // 26: orchestrator.PruneBotsRequest
+	(*PruneBotsResponse)(nil),        // 27: orchestrator.PruneBotsResponse
+	(*PrunedItem)(nil),               // 28: orchestrator.PrunedItem
+	nil,                              // 29: orchestrator.DeployRequest.EnvEntry
+	(*timestamppb.Timestamp)(nil),    // 30: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),      // 31: google.protobuf.Duration
+}
+var file_proto_orchestrator_proto_depIdxs = []int32{
+	29, // 0: orchestrator.DeployRequest.env:type_name -> orchestrator.DeployRequest.EnvEntry
+	3,  // 1: orchestrator.LogResponse.source:type_name -> orchestrator.LogSource
+	1,  // 2: orchestrator.LogResponse.status:type_name -> orchestrator.LogStatus
+	2,  // 3: orchestrator.LogResponse.phase:type_name -> orchestrator.DeployPhase
+	30, // 4: orchestrator.LogResponse.timestamp:type_name -> google.protobuf.Timestamp
+	8,  // 5: orchestrator.LogResponse.summary:type_name -> orchestrator.JobSummary
+	4,  // 6: orchestrator.JobSummary.state:type_name -> orchestrator.JobState
+	2,  // 7: orchestrator.JobSummary.phase:type_name -> orchestrator.DeployPhase
+	0,  // 8: orchestrator.JobSummary.failure_reason:type_name -> orchestrator.FailureReason
+	31, // 9: orchestrator.JobSummary.queued_duration:type_name -> google.protobuf.Duration
+	31, // 10: orchestrator.JobSummary.clone_duration:type_name -> google.protobuf.Duration
+	31, // 11: orchestrator.JobSummary.install_duration:type_name -> google.protobuf.Duration
+	31, // 12: orchestrator.JobSummary.run_duration:type_name -> google.protobuf.Duration
+	31, // 13: orchestrator.JobSummary.total_duration:type_name -> google.protobuf.Duration
+	4,  // 14: orchestrator.Job.state:type_name -> orchestrator.JobState
+	30, // 15: orchestrator.Job.started_at:type_name -> google.protobuf.Timestamp
+	30, // 16: orchestrator.Job.finished_at:type_name -> google.protobuf.Timestamp
+	8,  // 17: orchestrator.Job.summary:type_name -> orchestrator.JobSummary
+	9,  // 18: orchestrator.ListJobsResponse.jobs:type_name -> orchestrator.Job
+	30, // 19: orchestrator.LogEntry.time:type_name -> google.protobuf.Timestamp
+	3,  // 20: orchestrator.LogEntry.source:type_name -> orchestrator.LogSource
+	1,  // 21: orchestrator.LogEntry.status:type_name -> orchestrator.LogStatus
+	2,  // 22: orchestrator.LogEntry.phase:type_name -> orchestrator.DeployPhase
+	6,  // 23: orchestrator.Schedule.bot:type_name -> orchestrator.DeployRequest
+	5,  // 24: orchestrator.Schedule.misfire_policy:type_name -> orchestrator.MisfirePolicy
+	30, // 25: orchestrator.Schedule.created_at:type_name -> google.protobuf.Timestamp
+	30, // 26: orchestrator.Schedule.next_run_at:type_name -> google.protobuf.Timestamp
+	30, // 27: orchestrator.Schedule.last_run_at:type_name -> google.protobuf.Timestamp
+	6,  // 28: orchestrator.CreateScheduleRequest.bot:type_name -> orchestrator.DeployRequest
+	5,  // 29: orchestrator.CreateScheduleRequest.misfire_policy:type_name -> orchestrator.MisfirePolicy
+	17, // 30: orchestrator.ListSchedulesResponse.schedules:type_name -> orchestrator.Schedule
+	31, // 31: orchestrator.PruneBotsRequest.ttl:type_name -> google.protobuf.Duration
+	28, // 32: orchestrator.PruneBotsResponse.items:type_name -> orchestrator.PrunedItem
+	30, // 33: orchestrator.PrunedItem.last_used_at:type_name -> google.protobuf.Timestamp
+	6,  // 34: orchestrator.OrchestratorService.ExecuteDeploy:input_type -> orchestrator.DeployRequest
+	10, // 35: orchestrator.OrchestratorService.GetJob:input_type -> orchestrator.GetJobRequest
+	11, // 36: orchestrator.OrchestratorService.ListJobs:input_type -> orchestrator.ListJobsRequest
+	13, // 37: orchestrator.OrchestratorService.CancelJob:input_type -> orchestrator.CancelJobRequest
+	14, // 38: orchestrator.OrchestratorService.AttachLogs:input_type -> orchestrator.AttachLogsRequest
+	15, // 39: orchestrator.OrchestratorService.GetJobLogs:input_type -> orchestrator.GetJobLogsRequest
+	18, // 40: orchestrator.OrchestratorService.CreateSchedule:input_type -> orchestrator.CreateScheduleRequest
+	19, // 41: orchestrator.OrchestratorService.ListSchedules:input_type -> orchestrator.ListSchedulesRequest
+	21, // 42: orchestrator.OrchestratorService.DeleteSchedule:input_type -> orchestrator.DeleteScheduleRequest
+	23, // 43: orchestrator.OrchestratorService.PauseSchedule:input_type -> orchestrator.PauseScheduleRequest
+	24, // 44: orchestrator.OrchestratorService.DeleteBotVersion:input_type -> orchestrator.DeleteBotVersionRequest
+	26, // 45: orchestrator.OrchestratorService.PruneBots:input_type -> orchestrator.PruneBotsRequest
+	7,  // 46: orchestrator.OrchestratorService.ExecuteDeploy:output_type -> orchestrator.LogResponse
+	9,  // 47: orchestrator.OrchestratorService.GetJob:output_type -> orchestrator.Job
+	12, // 48: orchestrator.OrchestratorService.ListJobs:output_type -> orchestrator.ListJobsResponse
+	9,  // 49: orchestrator.OrchestratorService.CancelJob:output_type -> orchestrator.Job
+	7,  // 50: orchestrator.OrchestratorService.AttachLogs:output_type -> orchestrator.LogResponse
+	16, // 51: orchestrator.OrchestratorService.GetJobLogs:output_type -> orchestrator.LogEntry
+	17, // 52: orchestrator.OrchestratorService.CreateSchedule:output_type -> orchestrator.Schedule
+	20, // 53: orchestrator.OrchestratorService.ListSchedules:output_type -> orchestrator.ListSchedulesResponse
+	22, // 54: orchestrator.OrchestratorService.DeleteSchedule:output_type -> orchestrator.DeleteScheduleResponse
+	17, // 55: orchestrator.OrchestratorService.PauseSchedule:output_type -> orchestrator.Schedule
+	25, // 56: orchestrator.OrchestratorService.DeleteBotVersion:output_type -> orchestrator.DeleteBotVersionResponse
+	27, // 57: orchestrator.OrchestratorService.PruneBots:output_type -> orchestrator.PruneBotsResponse
+	46, // [46:58] is the sub-list for method output_type
+	34, // [34:46] is the sub-list for method input_type
+	34, // [34:34] is the sub-list for extension type_name
+	34, // [34:34] is the sub-list for extension extendee
+	0,  // [0:34] is the sub-list for field type_name
+}
+
+func init() { file_proto_orchestrator_proto_init() }
+func file_proto_orchestrator_proto_init() {
+	if File_proto_orchestrator_proto != nil {
+		return
+	}
+	file_proto_orchestrator_proto_msgTypes[1].OneofWrappers = []any{
+		(*LogResponse_Summary)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_orchestrator_proto_rawDesc), len(file_proto_orchestrator_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   2,
+			NumEnums:      6,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_orchestrator_proto_goTypes,
 		DependencyIndexes: file_proto_orchestrator_proto_depIdxs,
+		EnumInfos:         file_proto_orchestrator_proto_enumTypes,
 		MessageInfos:      file_proto_orchestrator_proto_msgTypes,
 	}.Build()
 	File_proto_orchestrator_proto = out.File
@@ -4,4 +4,15 @@ type Bot struct {
 	BotID   string `json:"bot_id"`
 	GitRepo string `json:"git_repo"`
 	Version string `json:"version"`
+	// ForceReclone descarta o clone local e clona a versão novamente.
+	ForceReclone bool `json:"force_reclone"`
+	// Credential é o nome de uma credencial Git cadastrada no agente.
+	Credential string `json:"credential,omitempty"`
+	// Env são variáveis de ambiente extras para o processo do bot.
+	Env map[string]string `json:"env,omitempty"`
+	// SecretRefs são segredos do agente injetados no ambiente do bot, no
+	// formato "NOME" ou "VAR=nome".
+	SecretRefs []string `json:"secret_refs,omitempty"`
+	// TimeoutSeconds sobrepõe o tempo limite do bot.yaml e do agente.
+	TimeoutSeconds uint32 `json:"timeout_seconds,omitempty"`
 }